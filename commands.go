@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	db "go-todo/db/sqlc"
+	"go-todo/util/config"
+	"go-todo/util/jwt"
+	"go-todo/util/registration"
+	"go-todo/util/tokengc"
+)
+
+// Runs the command given on the command line instead of starting the server.
+// Returns false if args has no command.
+func runCommand(args []string, queries *db.Queries, cfg *config.Config) (bool, error) {
+	if len(args) == 0 {
+		return false, nil
+	}
+
+	switch args[0] {
+	case "gc-tokens":
+		result, err := tokengc.New(queries, cfg).Run(context.Background())
+		if err != nil {
+			return true, err
+		}
+		fmt.Printf(
+			"Deleted %d expired and %d used jwts and %d ended sessions\n",
+			result.Expired,
+			result.Used,
+			result.Sessions,
+		)
+	case "keys":
+		return true, runKeysCommand(args[1:], cfg)
+	case "create-admin":
+		return true, runCreateAdminCommand(args[1:], queries)
+	default:
+		return true, fmt.Errorf("unknown command: %v", args[0])
+	}
+	return true, nil
+}
+
+// keys rotate [EdDSA|RS256] adds a new signing key, keys prune deletes the
+// keys no valid access token can be signed with anymore.
+func runKeysCommand(args []string, cfg *config.Config) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing keys command, expected rotate or prune")
+	}
+
+	switch args[0] {
+	case "rotate":
+		algorithm := cfg.JwtSigningAlgorithm
+		if len(args) > 1 {
+			algorithm = args[1]
+		}
+		kid, err := jwt.GenerateKeyFile(cfg.JwtKeyDir, algorithm)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created %v key %v in %v\n", algorithm, kid, cfg.JwtKeyDir)
+	case "prune":
+		// Impersonation tokens are signed with the same keys.
+		maxTokenAge := time.Duration(max(cfg.AccessTokenLifeSpan, cfg.ImpersonationLifeSpan)) * time.Minute
+		pruned, err := jwt.PruneKeyFiles(cfg.JwtKeyDir, maxTokenAge)
+		for _, kid := range pruned {
+			fmt.Printf("Deleted key %v\n", kid)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Deleted %d keys\n", len(pruned))
+	default:
+		return fmt.Errorf("unknown keys command: %v", args[0])
+	}
+	return nil
+}
+
+// create-admin <username> creates an admin. The password is read from the
+// first line of stdin so it doesn't end up in the shell history.
+func runCreateAdminCommand(args []string, queries *db.Queries) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected create-admin <username>")
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		return errors.New("no password given")
+	}
+	password := strings.TrimRight(scanner.Text(), "\r")
+
+	user, err := registration.CreateAdmin(context.Background(), queries, args[0], password)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created admin %v (%v)\n", user.Username, user.ID)
+	return nil
+}
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS time_entries(
+    id TEXT PRIMARY KEY,
+    todo_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP,
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (todo_id) REFERENCES todos(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+
+-- Only one running timer (entry without ended_at) per user.
+CREATE UNIQUE INDEX IF NOT EXISTS time_entries_running_user_idx
+ON time_entries(user_id) WHERE ended_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS time_entries_todo_idx ON time_entries(todo_id);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS list_templates(
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT,
+    is_global BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- due_offset_seconds is relative to the anchor date given when the template
+-- is instantiated.
+CREATE TABLE IF NOT EXISTS template_todos(
+    id TEXT PRIMARY KEY,
+    template_id TEXT NOT NULL,
+    parent_id TEXT,
+    title TEXT NOT NULL,
+    description TEXT,
+    due_offset_seconds BIGINT,
+    FOREIGN KEY (template_id) REFERENCES list_templates(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES template_todos(id) ON DELETE CASCADE
+);
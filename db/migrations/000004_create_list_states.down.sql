@@ -0,0 +1,2 @@
+ALTER TABLE todos DROP COLUMN IF EXISTS state_id;
+DROP TABLE IF EXISTS list_states;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS list_states(
+    id TEXT PRIMARY KEY,
+    list_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    is_done BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (list_id, name),
+    FOREIGN KEY (list_id) REFERENCES lists(id) ON DELETE CASCADE
+);
+
+ALTER TABLE todos
+ADD COLUMN IF NOT EXISTS state_id TEXT REFERENCES list_states(id) ON DELETE SET NULL;
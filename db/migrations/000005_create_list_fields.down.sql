@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS todo_field_values;
+DROP TABLE IF EXISTS list_fields;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS password_history(
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS password_history_user_id_idx ON password_history(user_id, created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS revoked_user_tokens;
+DROP TABLE IF EXISTS revoked_access_tokens;
\ No newline at end of file
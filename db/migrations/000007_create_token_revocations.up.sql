@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS revoked_access_tokens(
+    jti TEXT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- Access tokens of the user issued before revoked_before are revoked.
+CREATE TABLE IF NOT EXISTS revoked_user_tokens(
+    user_id TEXT PRIMARY KEY,
+    revoked_before TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS revoked_token_families;
+DROP INDEX IF EXISTS jwt_tokens_user_id_idx;
+ALTER TABLE jwt_tokens
+DROP COLUMN IF EXISTS user_agent,
+DROP COLUMN IF EXISTS ip;
\ No newline at end of file
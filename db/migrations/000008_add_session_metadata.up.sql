@@ -0,0 +1,11 @@
+ALTER TABLE jwt_tokens
+ADD COLUMN IF NOT EXISTS ip TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS user_agent TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS jwt_tokens_user_id_idx ON jwt_tokens(user_id);
+
+-- Access tokens belonging to a revoked session (refresh token family).
+CREATE TABLE IF NOT EXISTS revoked_token_families(
+    family TEXT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
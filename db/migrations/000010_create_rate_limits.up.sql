@@ -0,0 +1,7 @@
+-- One row per rate limited key. hits counts the requests in the window that
+-- ends at reset_at.
+CREATE TABLE IF NOT EXISTS rate_limits(
+    key TEXT PRIMARY KEY,
+    hits INTEGER NOT NULL,
+    reset_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS password_reset_tokens;
+DROP INDEX IF EXISTS users_email_key;
+ALTER TABLE users
+DROP COLUMN IF EXISTS email;
\ No newline at end of file
@@ -0,0 +1,16 @@
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS email TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users(LOWER(email));
+
+-- Only the hash of a reset token is stored. The token itself is mailed to the
+-- user.
+CREATE TABLE IF NOT EXISTS password_reset_tokens(
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
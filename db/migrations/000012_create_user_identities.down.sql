@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS oidc_states;
+DROP INDEX IF EXISTS user_identities_user_id_idx;
+DROP TABLE IF EXISTS user_identities;
\ No newline at end of file
@@ -0,0 +1,19 @@
+-- Links an account at an external identity provider to a user.
+CREATE TABLE IF NOT EXISTS user_identities(
+    issuer TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (issuer, subject),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS user_identities_user_id_idx ON user_identities(user_id);
+
+-- Logins started at the identity provider that haven't come back yet.
+CREATE TABLE IF NOT EXISTS oidc_states(
+    state TEXT PRIMARY KEY,
+    nonce TEXT NOT NULL,
+    code_verifier TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
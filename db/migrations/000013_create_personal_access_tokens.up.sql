@@ -0,0 +1,13 @@
+-- Long lived tokens for scripts. Only the hash of a token is stored, the
+-- token itself is shown once when it is created.
+CREATE TABLE IF NOT EXISTS personal_access_tokens(
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
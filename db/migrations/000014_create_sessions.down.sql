@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS sessions_user_id_idx;
+DROP TABLE IF EXISTS sessions;
\ No newline at end of file
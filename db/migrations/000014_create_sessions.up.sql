@@ -0,0 +1,25 @@
+-- One row per refresh token family. The jwt_tokens rows of a family come and
+-- go with every refresh, the session keeps where it was created.
+CREATE TABLE IF NOT EXISTS sessions(
+    family TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    device_name TEXT NOT NULL DEFAULT '',
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions(user_id);
+
+INSERT INTO sessions (family, user_id, ip, user_agent, created_at, last_used_at)
+SELECT family,
+    MIN(user_id),
+    (ARRAY_AGG(ip ORDER BY created_at))[1],
+    (ARRAY_AGG(user_agent ORDER BY created_at))[1],
+    MIN(created_at),
+    MAX(created_at)
+FROM jwt_tokens
+GROUP BY family
+ON CONFLICT DO NOTHING;
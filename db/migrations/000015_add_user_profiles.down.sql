@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS email_verification_tokens;
+ALTER TABLE users
+DROP COLUMN IF EXISTS email_verified_at,
+DROP COLUMN IF EXISTS locale,
+DROP COLUMN IF EXISTS timezone,
+DROP COLUMN IF EXISTS display_name;
\ No newline at end of file
@@ -0,0 +1,25 @@
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS display_name TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC',
+ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT 'en',
+ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+
+-- Addresses set before verification existed were never confirmed, and could
+-- have been claimed by someone else than their owner. They're removed so
+-- password resets and OIDC linking only see confirmed addresses, users add
+-- theirs again through verification.
+UPDATE users
+SET email = NULL
+WHERE email IS NOT NULL AND email_verified_at IS NULL;
+
+-- A new address waiting to be confirmed. users.email only changes once the
+-- token mailed to the new address is used.
+CREATE TABLE IF NOT EXISTS email_verification_tokens(
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    email TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
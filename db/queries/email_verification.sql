@@ -0,0 +1,11 @@
+-- name: CreateEmailVerificationToken :exec
+INSERT INTO email_verification_tokens (id, user_id, email, token_hash, expires_at)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetValidEmailVerificationToken :one
+SELECT * FROM email_verification_tokens
+WHERE token_hash = $1 AND expires_at > $2;
+
+-- name: DeleteEmailVerificationTokensByUserId :exec
+DELETE FROM email_verification_tokens
+WHERE user_id = $1;
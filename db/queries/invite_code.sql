@@ -0,0 +1,20 @@
+-- name: CreateInviteCode :one
+INSERT INTO invite_codes (id, code_hash, created_by, max_uses, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetInviteCodes :many
+SELECT * FROM invite_codes
+ORDER BY created_at;
+
+-- name: UseInviteCode :one
+-- Takes one use of the code. Concurrent registrations wait for the row lock,
+-- so a code is never used more than max_uses times.
+UPDATE invite_codes
+SET uses = uses + 1
+WHERE code_hash = $1 AND uses < max_uses AND expires_at > $2
+RETURNING id;
+
+-- name: DeleteInviteCode :execrows
+DELETE FROM invite_codes
+WHERE id = $1;
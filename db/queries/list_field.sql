@@ -0,0 +1,44 @@
+-- name: CreateListField :one
+INSERT INTO list_fields (id, list_id, name, field_type, options, position)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetListField :one
+SELECT * FROM list_fields
+WHERE id = $1 AND list_id = $2;
+
+-- name: GetListFieldsByListId :many
+SELECT * FROM list_fields
+WHERE list_id = $1
+ORDER BY position, created_at;
+
+-- name: UpdateListField :one
+UPDATE list_fields
+SET name = $2, options = $3, position = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteListField :execrows
+DELETE FROM list_fields
+WHERE id = $1;
+
+-- name: UpsertTodoFieldValue :one
+INSERT INTO todo_field_values (todo_id, field_id, value)
+VALUES ($1, $2, $3)
+ON CONFLICT (todo_id, field_id)
+DO UPDATE SET value = EXCLUDED.value, updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteTodoFieldValue :execrows
+DELETE FROM todo_field_values
+WHERE todo_id = $1 AND field_id = $2;
+
+-- name: GetTodoFieldValuesByListId :many
+SELECT v.* FROM todo_field_values v
+INNER JOIN list_fields f ON f.id = v.field_id
+WHERE f.list_id = $1;
+
+-- name: DeleteTodoFieldValuesNotInOptions :execrows
+-- Drops select values that are no longer one of the field's options.
+DELETE FROM todo_field_values
+WHERE field_id = sqlc.arg(field_id) AND NOT (value = ANY(sqlc.arg(options)::text[]));
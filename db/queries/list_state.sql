@@ -0,0 +1,30 @@
+-- name: CreateListState :one
+INSERT INTO list_states (id, list_id, name, position, is_done)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetListState :one
+SELECT * FROM list_states
+WHERE id = $1 AND list_id = $2;
+
+-- name: GetListStatesByListId :many
+SELECT * FROM list_states
+WHERE list_id = $1
+ORDER BY position, created_at;
+
+-- name: UpdateListState :one
+UPDATE list_states
+SET name = $2, position = $3, is_done = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteListState :execrows
+DELETE FROM list_states
+WHERE id = $1;
+
+-- name: SyncTodosCompletedByStateId :exec
+UPDATE todos
+SET completed = $2,
+    completed_at = CASE WHEN $2 THEN COALESCE(completed_at, CURRENT_TIMESTAMP) ELSE NULL END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE state_id = $1 AND completed != $2;
@@ -0,0 +1,29 @@
+-- name: CreateOidcState :exec
+INSERT INTO oidc_states (state, nonce, code_verifier, expires_at)
+VALUES ($1, $2, $3, $4);
+
+-- name: UseOidcState :one
+-- Deletes the state so it can only be used once.
+DELETE FROM oidc_states
+WHERE state = $1 AND expires_at > $2
+RETURNING *;
+
+-- name: DeleteExpiredOidcStates :exec
+DELETE FROM oidc_states
+WHERE expires_at < $1;
+
+-- name: CreateUserIdentity :exec
+INSERT INTO user_identities (issuer, subject, user_id)
+VALUES ($1, $2, $3);
+
+-- name: GetUserIdentity :one
+SELECT * FROM user_identities
+WHERE issuer = $1 AND subject = $2;
+
+-- name: HasUserIdentityFromIssuer :one
+-- Tells whether the user is linked to an identity of an issuer whose name
+-- starts with issuer_prefix.
+SELECT EXISTS (
+    SELECT 1 FROM user_identities
+    WHERE user_id = sqlc.arg(user_id) AND starts_with(issuer, sqlc.arg(issuer_prefix)::text)
+);
@@ -0,0 +1,19 @@
+-- name: CreatePasswordHistory :exec
+INSERT INTO password_history (id, user_id, password_hash)
+VALUES ($1, $2, $3);
+
+-- name: GetPasswordHistoryByUserId :many
+SELECT password_hash FROM password_history
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: DeletePasswordHistoryExceptNewest :exec
+-- Keeps only the newest entries of the user's password history.
+DELETE FROM password_history
+WHERE user_id = sqlc.arg(user_id) AND id NOT IN (
+    SELECT id FROM password_history
+    WHERE user_id = sqlc.arg(user_id)
+    ORDER BY created_at DESC
+    LIMIT sqlc.arg(keep)
+);
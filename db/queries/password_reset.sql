@@ -0,0 +1,18 @@
+-- name: CreatePasswordResetToken :exec
+INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetValidPasswordResetToken :one
+SELECT * FROM password_reset_tokens
+WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2;
+
+-- name: UsePasswordResetToken :execrows
+-- Fails if the token has already been used, so it works only once even with
+-- concurrent requests.
+UPDATE password_reset_tokens
+SET used_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND used_at IS NULL;
+
+-- name: DeletePasswordResetTokensByUserId :exec
+DELETE FROM password_reset_tokens
+WHERE user_id = $1;
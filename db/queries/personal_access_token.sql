@@ -0,0 +1,26 @@
+-- name: CreatePersonalAccessToken :one
+INSERT INTO personal_access_tokens (id, user_id, name, token_hash, scopes, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetPersonalAccessTokensByUserId :many
+SELECT * FROM personal_access_tokens
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: GetValidPersonalAccessToken :one
+SELECT pat.id, pat.user_id, pat.scopes, pat.expires_at, pat.created_at, users.username
+FROM personal_access_tokens pat
+JOIN users ON users.id = pat.user_id
+WHERE pat.token_hash = $1 AND (pat.expires_at IS NULL OR pat.expires_at > $2);
+
+-- name: TouchPersonalAccessToken :exec
+-- Only writes when the last use is older than used_before, so busy tokens
+-- don't cause a write per request.
+UPDATE personal_access_tokens
+SET last_used_at = sqlc.arg(used_at)
+WHERE id = sqlc.arg(id) AND (last_used_at IS NULL OR last_used_at < sqlc.arg(used_before));
+
+-- name: DeletePersonalAccessToken :execrows
+DELETE FROM personal_access_tokens
+WHERE id = $1 AND user_id = $2;
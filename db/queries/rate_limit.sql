@@ -0,0 +1,16 @@
+-- name: HitRateLimit :one
+-- Counts a request in the window ending at reset_at. A new window starts the
+-- count over.
+INSERT INTO rate_limits (key, hits, reset_at)
+VALUES ($1, 1, $2)
+ON CONFLICT (key) DO UPDATE
+SET hits = CASE
+        WHEN rate_limits.reset_at = EXCLUDED.reset_at THEN rate_limits.hits + 1
+        ELSE 1
+    END,
+    reset_at = EXCLUDED.reset_at
+RETURNING hits, reset_at;
+
+-- name: DeleteExpiredRateLimits :execrows
+DELETE FROM rate_limits
+WHERE reset_at < CURRENT_TIMESTAMP;
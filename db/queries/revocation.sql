@@ -0,0 +1,43 @@
+-- name: RevokeAccessToken :exec
+INSERT INTO revoked_access_tokens (jti, expires_at)
+VALUES ($1, $2)
+ON CONFLICT (jti) DO NOTHING;
+
+-- name: RevokeUserTokens :exec
+INSERT INTO revoked_user_tokens (user_id, revoked_before, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id) DO UPDATE
+SET revoked_before = GREATEST(revoked_user_tokens.revoked_before, EXCLUDED.revoked_before),
+    expires_at = GREATEST(revoked_user_tokens.expires_at, EXCLUDED.expires_at);
+
+-- name: RevokeTokenFamily :exec
+INSERT INTO revoked_token_families (family, expires_at)
+VALUES ($1, $2)
+ON CONFLICT (family) DO UPDATE
+SET expires_at = GREATEST(revoked_token_families.expires_at, EXCLUDED.expires_at);
+
+-- name: IsAccessTokenRevoked :one
+SELECT EXISTS (
+    SELECT 1 FROM revoked_access_tokens
+    WHERE jti = sqlc.arg(jti) AND expires_at > CURRENT_TIMESTAMP
+) OR EXISTS (
+    SELECT 1 FROM revoked_token_families
+    WHERE family = sqlc.arg(family) AND expires_at > CURRENT_TIMESTAMP
+) OR EXISTS (
+    SELECT 1 FROM revoked_user_tokens
+    WHERE user_id = sqlc.arg(user_id)
+        AND revoked_before > sqlc.arg(issued_at)
+        AND expires_at > CURRENT_TIMESTAMP
+) AS revoked;
+
+-- name: DeleteExpiredRevokedAccessTokens :execrows
+DELETE FROM revoked_access_tokens
+WHERE expires_at <= CURRENT_TIMESTAMP;
+
+-- name: DeleteExpiredRevokedUserTokens :execrows
+DELETE FROM revoked_user_tokens
+WHERE expires_at <= CURRENT_TIMESTAMP;
+
+-- name: DeleteExpiredRevokedTokenFamilies :execrows
+DELETE FROM revoked_token_families
+WHERE expires_at <= CURRENT_TIMESTAMP;
@@ -0,0 +1,34 @@
+-- name: CreateSession :exec
+INSERT INTO sessions (family, user_id, device_name, ip, user_agent, created_at, last_used_at)
+VALUES ($1, $2, $3, $4, $5, $6, $6);
+
+-- name: GetSession :one
+SELECT * FROM sessions
+WHERE family = $1;
+
+-- name: TouchSession :exec
+UPDATE sessions
+SET last_used_at = $2
+WHERE family = $1;
+
+-- name: GetSessionFamiliesToEvict :many
+-- Returns the active sessions of the user except the newest keep ones,
+-- oldest first.
+SELECT family FROM sessions
+WHERE user_id = sqlc.arg(user_id) AND EXISTS (
+    SELECT 1 FROM jwt_tokens
+    WHERE jwt_tokens.family = sessions.family
+        AND NOT jwt_tokens.is_used
+        AND jwt_tokens.expires_at > CURRENT_TIMESTAMP
+)
+ORDER BY created_at DESC
+OFFSET sqlc.arg(keep);
+
+-- name: DeleteOrphanedSessions :execrows
+-- Deletes the sessions whose refresh tokens are all gone. Sessions created
+-- after created_before are skipped as their first token may not be saved yet.
+DELETE FROM sessions
+WHERE created_at < sqlc.arg(created_before) AND NOT EXISTS (
+    SELECT 1 FROM jwt_tokens
+    WHERE jwt_tokens.family = sessions.family
+);
@@ -0,0 +1,55 @@
+-- name: GetTodoStatsByListIds :one
+SELECT
+    COUNT(*) FILTER (WHERE NOT completed)::BIGINT AS open_count,
+    COUNT(*) FILTER (WHERE completed)::BIGINT AS completed_count,
+    COUNT(*) FILTER (
+        WHERE NOT completed AND complete_before < CURRENT_TIMESTAMP
+    )::BIGINT AS overdue_count,
+    COALESCE(
+        AVG(EXTRACT(EPOCH FROM (completed_at - created_at))) FILTER (
+            WHERE completed AND completed_at IS NOT NULL
+        ),
+        0
+    )::FLOAT8 AS avg_completion_seconds
+FROM todos
+WHERE list_id = ANY(sqlc.arg(list_ids)::text[]);
+
+-- name: GetCompletionBucketsByListIds :many
+WITH created AS (
+    SELECT date_trunc(sqlc.arg(bucket)::text, created_at) AS bucket_start, COUNT(*) AS cnt
+    FROM todos
+    WHERE list_id = ANY(sqlc.arg(list_ids)::text[])
+        AND created_at >= sqlc.arg(range_start)::timestamp
+        AND created_at < sqlc.arg(range_end)::timestamp
+    GROUP BY 1
+), completed AS (
+    SELECT date_trunc(sqlc.arg(bucket)::text, completed_at) AS bucket_start, COUNT(*) AS cnt
+    FROM todos
+    WHERE list_id = ANY(sqlc.arg(list_ids)::text[])
+        AND completed
+        AND completed_at >= sqlc.arg(range_start)::timestamp
+        AND completed_at < sqlc.arg(range_end)::timestamp
+    GROUP BY 1
+)
+SELECT
+    COALESCE(cr.bucket_start, co.bucket_start)::timestamp AS bucket_start,
+    COALESCE(cr.cnt, 0)::BIGINT AS created_count,
+    COALESCE(co.cnt, 0)::BIGINT AS completed_count
+FROM created cr
+FULL OUTER JOIN completed co ON cr.bucket_start = co.bucket_start
+ORDER BY 1;
+
+-- name: GetTodoStatsByUserForList :many
+SELECT
+    t.user_id,
+    u.username,
+    COUNT(*) FILTER (WHERE NOT t.completed)::BIGINT AS open_count,
+    COUNT(*) FILTER (WHERE t.completed)::BIGINT AS completed_count,
+    COUNT(*) FILTER (
+        WHERE NOT t.completed AND t.complete_before < CURRENT_TIMESTAMP
+    )::BIGINT AS overdue_count
+FROM todos t
+JOIN users u ON t.user_id = u.id
+WHERE t.list_id = $1
+GROUP BY t.user_id, u.username
+ORDER BY u.username;
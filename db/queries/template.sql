@@ -0,0 +1,26 @@
+-- name: CreateListTemplate :one
+INSERT INTO list_templates (id, user_id, title, description, is_global)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetListTemplate :one
+SELECT * FROM list_templates
+WHERE id = $1;
+
+-- name: GetListTemplatesAccessible :many
+SELECT * FROM list_templates
+WHERE user_id = $1 OR is_global
+ORDER BY title;
+
+-- name: DeleteListTemplate :execrows
+DELETE FROM list_templates
+WHERE id = $1;
+
+-- name: CreateTemplateTodo :one
+INSERT INTO template_todos (id, template_id, parent_id, title, description, due_offset_seconds)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetTemplateTodosByTemplateId :many
+SELECT * FROM template_todos
+WHERE template_id = $1;
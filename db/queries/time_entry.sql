@@ -0,0 +1,51 @@
+-- name: CreateTimeEntry :one
+INSERT INTO time_entries (id, todo_id, user_id, started_at, ended_at, note)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetTimeEntryById :one
+SELECT * FROM time_entries
+WHERE id = $1;
+
+-- name: GetRunningTimeEntryByUserId :one
+SELECT * FROM time_entries
+WHERE user_id = $1 AND ended_at IS NULL;
+
+-- name: StopTimeEntry :one
+UPDATE time_entries
+SET ended_at = $2
+WHERE id = $1 AND ended_at IS NULL
+RETURNING *;
+
+-- name: GetTimeEntriesByTodoId :many
+SELECT * FROM time_entries
+WHERE todo_id = $1
+ORDER BY started_at;
+
+-- name: GetTimeEntriesByUserIdInRange :many
+SELECT * FROM time_entries
+WHERE user_id = $1
+    AND started_at >= sqlc.arg(range_start)
+    AND started_at < sqlc.arg(range_end)
+ORDER BY started_at;
+
+-- name: DeleteTimeEntryByIdWithUserId :execrows
+DELETE FROM time_entries
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetTimeTotalByTodoId :one
+SELECT COALESCE(SUM(EXTRACT(EPOCH FROM (ended_at - started_at))), 0)::BIGINT AS seconds
+FROM time_entries
+WHERE todo_id = $1 AND ended_at IS NOT NULL;
+
+-- name: GetTimeTotalsByListId :many
+SELECT te.todo_id, COALESCE(SUM(EXTRACT(EPOCH FROM (te.ended_at - te.started_at))), 0)::BIGINT AS seconds
+FROM time_entries te
+JOIN todos t ON te.todo_id = t.id
+WHERE t.list_id = $1 AND te.ended_at IS NOT NULL
+GROUP BY te.todo_id;
+
+-- name: GetTimeTotalByUserId :one
+SELECT COALESCE(SUM(EXTRACT(EPOCH FROM (ended_at - started_at))), 0)::BIGINT AS seconds
+FROM time_entries
+WHERE user_id = $1 AND ended_at IS NOT NULL;
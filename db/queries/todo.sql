@@ -1,6 +1,6 @@
 -- name: CreateTodo :one
-INSERT INTO todos (id, list_id, user_id, parent_id, title, description, complete_before)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO todos (id, list_id, user_id, parent_id, title, description, complete_before, state_id, completed)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE((SELECT is_done FROM list_states WHERE id = $8), FALSE))
 RETURNING *;
 
 -- name: GetTodoByIdWithListId :one
@@ -23,11 +23,43 @@ SELECT * FROM todos
 WHERE list_id = ANY($1::text[]);
 
 -- name: UpdateTodo :one
+-- If the todo has a state, completed is derived from it. Otherwise the given
+-- completed value is used. completed_at is kept while the todo stays completed.
 UPDATE todos
-SET title = $1, description = $2, completed = $3, complete_before = $4, updated_at = CURRENT_TIMESTAMP, completed_at = CASE WHEN $3 THEN CURRENT_TIMESTAMP ELSE NULL END
-WHERE id = $5
+SET title = sqlc.arg(title),
+    description = sqlc.arg(description),
+    complete_before = sqlc.arg(complete_before),
+    state_id = sqlc.narg(state_id),
+    completed = COALESCE(
+        (SELECT s.is_done FROM list_states s WHERE s.id = sqlc.narg(state_id)),
+        sqlc.arg(completed)::boolean
+    ),
+    completed_at = CASE
+        WHEN NOT COALESCE(
+            (SELECT s.is_done FROM list_states s WHERE s.id = sqlc.narg(state_id)),
+            sqlc.arg(completed)::boolean
+        ) THEN NULL
+        WHEN completed THEN completed_at
+        ELSE CURRENT_TIMESTAMP
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
 RETURNING *;
 
+-- name: UpdateTodoState :one
+UPDATE todos
+SET state_id = s.id,
+    completed = s.is_done,
+    completed_at = CASE
+        WHEN NOT s.is_done THEN NULL
+        WHEN todos.completed THEN todos.completed_at
+        ELSE CURRENT_TIMESTAMP
+    END,
+    updated_at = CURRENT_TIMESTAMP
+FROM list_states s
+WHERE todos.id = sqlc.arg(id) AND s.id = sqlc.arg(state_id) AND s.list_id = todos.list_id
+RETURNING todos.*;
+
 -- name: DeleteTodo :exec
 DELETE FROM todos
 WHERE id = $1;
@@ -1,6 +1,6 @@
 -- name: CreateJwtToken :exec
-INSERT INTO jwt_tokens (jti, user_id, family, created_at, expires_at)
-VALUES ($1, $2, $3, $4, $5);
+INSERT INTO jwt_tokens (jti, user_id, family, created_at, expires_at, ip, user_agent)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
 
 -- name: GetJwtTokenByJti :one
 SELECT *
@@ -23,4 +23,48 @@ WHERE user_id = $1 AND family != $2;
 
 -- name: DeleteJwtTokensByUserId :exec
 DELETE FROM jwt_tokens
-WHERE user_id = $1;
\ No newline at end of file
+WHERE user_id = $1;
+
+-- name: GetSessionsByUserId :many
+-- A session is a token family that still has an unused refresh token. The
+-- newest token of the family tells where the session was last used from.
+SELECT sessions.family,
+    sessions.device_name,
+    sessions.ip AS created_ip,
+    sessions.user_agent AS created_user_agent,
+    sessions.created_at,
+    sessions.last_used_at,
+    MAX(jwt_tokens.expires_at)::timestamp AS expires_at,
+    (ARRAY_AGG(jwt_tokens.ip ORDER BY jwt_tokens.created_at DESC))[1]::text AS ip,
+    (ARRAY_AGG(jwt_tokens.user_agent ORDER BY jwt_tokens.created_at DESC))[1]::text AS user_agent
+FROM sessions
+JOIN jwt_tokens ON jwt_tokens.family = sessions.family
+WHERE sessions.user_id = $1
+GROUP BY sessions.family
+HAVING BOOL_OR(NOT jwt_tokens.is_used AND jwt_tokens.expires_at > CURRENT_TIMESTAMP)
+ORDER BY sessions.last_used_at DESC;
+
+-- name: DeleteJwtTokenByUserIdAndFamily :execrows
+DELETE FROM jwt_tokens
+WHERE user_id = $1 AND family = $2;
+-- name: DeleteExpiredJwtTokens :execrows
+-- Deletes at most batch_size expired tokens. Rows locked by other
+-- transactions are skipped.
+DELETE FROM jwt_tokens
+WHERE jti IN (
+    SELECT jti FROM jwt_tokens
+    WHERE expires_at < CURRENT_TIMESTAMP
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+);
+
+-- name: DeleteUsedJwtTokens :execrows
+-- Deletes at most batch_size used tokens created before used_before. Rows
+-- locked by other transactions are skipped.
+DELETE FROM jwt_tokens
+WHERE jti IN (
+    SELECT jti FROM jwt_tokens
+    WHERE is_used AND created_at < sqlc.arg(used_before)
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+);
@@ -0,0 +1,45 @@
+-- name: UpsertUserTotp :one
+-- Starts a new enrollment. An existing unconfirmed secret is replaced.
+INSERT INTO user_totp (user_id, secret)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET secret = EXCLUDED.secret, confirmed = FALSE, last_used_step = 0, created_at = CURRENT_TIMESTAMP
+WHERE user_totp.confirmed = FALSE
+RETURNING *;
+
+-- name: GetUserTotp :one
+SELECT * FROM user_totp
+WHERE user_id = $1;
+
+-- name: ConfirmUserTotp :exec
+UPDATE user_totp
+SET confirmed = TRUE, last_used_step = $2
+WHERE user_id = $1;
+
+-- name: UseUserTotpStep :execrows
+-- Fails if the step or a later one has already been used, so a code can't be
+-- replayed.
+UPDATE user_totp
+SET last_used_step = $2
+WHERE user_id = $1 AND last_used_step < $2;
+
+-- name: DeleteUserTotp :exec
+DELETE FROM user_totp
+WHERE user_id = $1;
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO recovery_codes (id, user_id, code_hash)
+VALUES ($1, $2, $3);
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes
+SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;
+
+-- name: CountUnusedRecoveryCodes :one
+SELECT COUNT(*) FROM recovery_codes
+WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: DeleteRecoveryCodesByUserId :exec
+DELETE FROM recovery_codes
+WHERE user_id = $1;
@@ -8,6 +8,11 @@ SELECT *
 FROM users
 WHERE username = $1;
 
+-- name: GetUserByEmail :one
+SELECT *
+FROM users
+WHERE LOWER(email) = LOWER($1);
+
 -- name: GetAllUsers :many
 SELECT id, username, is_admin, created_at
 FROM users;
@@ -18,10 +23,18 @@ VALUES ($1, $2, $3, $4)
 RETURNING id, username, is_admin, created_at;
 
 -- name: UpdateUser :one
+-- Changing the email forgets that the old one was verified.
 UPDATE users
-SET username = $2, is_admin = $3
+SET username = $2, is_admin = $3, email = $4,
+    email_verified_at = CASE WHEN email IS NOT DISTINCT FROM $4 THEN email_verified_at END,
+    display_name = $5, timezone = $6, locale = $7
 WHERE id = $1
-RETURNING id, username, is_admin, created_at;
+RETURNING id, username, is_admin, created_at, email, display_name, timezone, locale, email_verified_at;
+
+-- name: VerifyUserEmail :exec
+UPDATE users
+SET email = $2, email_verified_at = $3
+WHERE id = $1;
 
 -- name: UpdateUserPassword :exec
 UPDATE users
@@ -30,4 +43,42 @@ WHERE id = $1;
 
 -- name: DeleteUser :execrows
 DELETE FROM users
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: ListUsers :many
+-- Users whose username starts with username_prefix, sorted by sort_by
+-- ('username' or 'created_at'). Active sessions have a usable refresh token.
+SELECT id, username, is_admin, created_at, email, display_name, timezone, locale, email_verified_at,
+    (SELECT COUNT(*) FROM lists WHERE lists.user_id = users.id) AS list_count,
+    (SELECT COUNT(*) FROM sessions
+        WHERE sessions.user_id = users.id AND EXISTS (
+            SELECT 1 FROM jwt_tokens
+            WHERE jwt_tokens.family = sessions.family
+                AND NOT jwt_tokens.is_used
+                AND jwt_tokens.expires_at > CURRENT_TIMESTAMP
+        )
+    ) AS session_count
+FROM users
+WHERE starts_with(LOWER(username), LOWER(sqlc.arg(username_prefix)::text))
+    AND (sqlc.narg(is_admin)::boolean IS NULL OR is_admin = sqlc.narg(is_admin))
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by)::text = 'username' AND NOT sqlc.arg(sort_desc)::boolean THEN username END,
+    CASE WHEN sqlc.arg(sort_by)::text = 'username' AND sqlc.arg(sort_desc)::boolean THEN username END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'created_at' AND NOT sqlc.arg(sort_desc)::boolean THEN created_at END,
+    CASE WHEN sqlc.arg(sort_by)::text = 'created_at' AND sqlc.arg(sort_desc)::boolean THEN created_at END DESC,
+    id
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+WHERE starts_with(LOWER(username), LOWER(sqlc.arg(username_prefix)::text))
+    AND (sqlc.narg(is_admin)::boolean IS NULL OR is_admin = sqlc.narg(is_admin));
+
+-- name: GetPasswordHashFormats :many
+-- The algorithms and parameters of the stored hashes: argon2id hashes
+-- without their salt and key, bcrypt hashes up to their cost.
+SELECT DISTINCT (CASE
+    WHEN starts_with(password_hash, '$argon2id$') THEN regexp_replace(password_hash, '(\$[^$]*){2}$', '')
+    ELSE left(password_hash, 7)
+END)::text AS format
+FROM users;
@@ -8,6 +8,25 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type EmailVerificationToken struct {
+	ID        string           `json:"id"`
+	UserID    string           `json:"user_id"`
+	Email     string           `json:"email"`
+	TokenHash string           `json:"token_hash"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type InviteCode struct {
+	ID        string           `json:"id"`
+	CodeHash  string           `json:"code_hash"`
+	CreatedBy string           `json:"created_by"`
+	MaxUses   int32            `json:"max_uses"`
+	Uses      int32            `json:"uses"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
 type JwtToken struct {
 	Jti       string           `json:"jti"`
 	Family    string           `json:"family"`
@@ -15,6 +34,8 @@ type JwtToken struct {
 	IsUsed    bool             `json:"is_used"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
 	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	Ip        string           `json:"ip"`
+	UserAgent string           `json:"user_agent"`
 }
 
 type List struct {
@@ -26,11 +47,132 @@ type List struct {
 	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
 }
 
+type ListField struct {
+	ID        string           `json:"id"`
+	ListID    string           `json:"list_id"`
+	Name      string           `json:"name"`
+	FieldType string           `json:"field_type"`
+	Options   []string         `json:"options"`
+	Position  int32            `json:"position"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
 type ListShare struct {
 	ListID string `json:"list_id"`
 	UserID string `json:"user_id"`
 }
 
+type ListState struct {
+	ID        string           `json:"id"`
+	ListID    string           `json:"list_id"`
+	Name      string           `json:"name"`
+	Position  int32            `json:"position"`
+	IsDone    bool             `json:"is_done"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type ListTemplate struct {
+	ID          string           `json:"id"`
+	UserID      string           `json:"user_id"`
+	Title       string           `json:"title"`
+	Description pgtype.Text      `json:"description"`
+	IsGlobal    bool             `json:"is_global"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type OidcState struct {
+	State        string           `json:"state"`
+	Nonce        string           `json:"nonce"`
+	CodeVerifier string           `json:"code_verifier"`
+	ExpiresAt    pgtype.Timestamp `json:"expires_at"`
+}
+
+type PasswordHistory struct {
+	ID           string           `json:"id"`
+	UserID       string           `json:"user_id"`
+	PasswordHash string           `json:"password_hash"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+}
+
+type PasswordResetToken struct {
+	ID        string           `json:"id"`
+	UserID    string           `json:"user_id"`
+	TokenHash string           `json:"token_hash"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	UsedAt    pgtype.Timestamp `json:"used_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type PersonalAccessToken struct {
+	ID         string           `json:"id"`
+	UserID     string           `json:"user_id"`
+	Name       string           `json:"name"`
+	TokenHash  string           `json:"token_hash"`
+	Scopes     []string         `json:"scopes"`
+	ExpiresAt  pgtype.Timestamp `json:"expires_at"`
+	LastUsedAt pgtype.Timestamp `json:"last_used_at"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type RateLimit struct {
+	Key     string           `json:"key"`
+	Hits    int32            `json:"hits"`
+	ResetAt pgtype.Timestamp `json:"reset_at"`
+}
+
+type RecoveryCode struct {
+	ID        string           `json:"id"`
+	UserID    string           `json:"user_id"`
+	CodeHash  string           `json:"code_hash"`
+	UsedAt    pgtype.Timestamp `json:"used_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type RevokedAccessToken struct {
+	Jti       string           `json:"jti"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+}
+
+type RevokedTokenFamily struct {
+	Family    string           `json:"family"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+}
+
+type RevokedUserToken struct {
+	UserID        string           `json:"user_id"`
+	RevokedBefore pgtype.Timestamp `json:"revoked_before"`
+	ExpiresAt     pgtype.Timestamp `json:"expires_at"`
+}
+
+type Session struct {
+	Family     string           `json:"family"`
+	UserID     string           `json:"user_id"`
+	DeviceName string           `json:"device_name"`
+	Ip         string           `json:"ip"`
+	UserAgent  string           `json:"user_agent"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+	LastUsedAt pgtype.Timestamp `json:"last_used_at"`
+}
+
+type TemplateTodo struct {
+	ID               string      `json:"id"`
+	TemplateID       string      `json:"template_id"`
+	ParentID         pgtype.Text `json:"parent_id"`
+	Title            string      `json:"title"`
+	Description      pgtype.Text `json:"description"`
+	DueOffsetSeconds pgtype.Int8 `json:"due_offset_seconds"`
+}
+
+type TimeEntry struct {
+	ID        string           `json:"id"`
+	TodoID    string           `json:"todo_id"`
+	UserID    string           `json:"user_id"`
+	StartedAt pgtype.Timestamp `json:"started_at"`
+	EndedAt   pgtype.Timestamp `json:"ended_at"`
+	Note      pgtype.Text      `json:"note"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
 type Todo struct {
 	ID             string           `json:"id"`
 	ParentID       pgtype.Text      `json:"parent_id"`
@@ -43,12 +185,40 @@ type Todo struct {
 	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
 	CompleteBefore pgtype.Timestamp `json:"complete_before"`
 	CompletedAt    pgtype.Timestamp `json:"completed_at"`
+	StateID        pgtype.Text      `json:"state_id"`
+}
+
+type TodoFieldValue struct {
+	TodoID    string           `json:"todo_id"`
+	FieldID   string           `json:"field_id"`
+	Value     string           `json:"value"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
 }
 
 type User struct {
-	ID           string           `json:"id"`
-	Username     string           `json:"username"`
-	PasswordHash string           `json:"password_hash"`
-	IsAdmin      bool             `json:"is_admin"`
+	ID              string           `json:"id"`
+	Username        string           `json:"username"`
+	PasswordHash    string           `json:"password_hash"`
+	IsAdmin         bool             `json:"is_admin"`
+	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	Email           pgtype.Text      `json:"email"`
+	DisplayName     string           `json:"display_name"`
+	Timezone        string           `json:"timezone"`
+	Locale          string           `json:"locale"`
+	EmailVerifiedAt pgtype.Timestamp `json:"email_verified_at"`
+}
+
+type UserIdentity struct {
+	Issuer    string           `json:"issuer"`
+	Subject   string           `json:"subject"`
+	UserID    string           `json:"user_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type UserTotp struct {
+	UserID       string           `json:"user_id"`
+	Secret       string           `json:"secret"`
+	Confirmed    bool             `json:"confirmed"`
+	LastUsedStep int64            `json:"last_used_step"`
 	CreatedAt    pgtype.Timestamp `json:"created_at"`
 }
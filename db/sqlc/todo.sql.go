@@ -12,9 +12,9 @@ import (
 )
 
 const createTodo = `-- name: CreateTodo :one
-INSERT INTO todos (id, list_id, user_id, parent_id, title, description, complete_before)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, parent_id, list_id, user_id, title, description, completed, created_at, updated_at, complete_before, completed_at
+INSERT INTO todos (id, list_id, user_id, parent_id, title, description, complete_before, state_id, completed)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE((SELECT is_done FROM list_states WHERE id = $8), FALSE))
+RETURNING id, parent_id, list_id, user_id, title, description, completed, created_at, updated_at, complete_before, completed_at, state_id
 `
 
 type CreateTodoParams struct {
@@ -25,6 +25,7 @@ type CreateTodoParams struct {
 	Title          string           `json:"title"`
 	Description    pgtype.Text      `json:"description"`
 	CompleteBefore pgtype.Timestamp `json:"complete_before"`
+	StateID        pgtype.Text      `json:"state_id"`
 }
 
 func (q *Queries) CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, error) {
@@ -36,6 +37,7 @@ func (q *Queries) CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, e
 		arg.Title,
 		arg.Description,
 		arg.CompleteBefore,
+		arg.StateID,
 	)
 	var i Todo
 	err := row.Scan(
@@ -50,6 +52,7 @@ func (q *Queries) CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, e
 		&i.UpdatedAt,
 		&i.CompleteBefore,
 		&i.CompletedAt,
+		&i.StateID,
 	)
 	return i, err
 }
@@ -80,7 +83,7 @@ func (q *Queries) DeleteTodoByIdWithListId(ctx context.Context, arg DeleteTodoBy
 }
 
 const getTodoByIdWithListId = `-- name: GetTodoByIdWithListId :one
-SELECT id, parent_id, list_id, user_id, title, description, completed, created_at, updated_at, complete_before, completed_at FROM todos
+SELECT id, parent_id, list_id, user_id, title, description, completed, created_at, updated_at, complete_before, completed_at, state_id FROM todos
 WHERE id = $1 AND list_id = $2
 `
 
@@ -104,12 +107,13 @@ func (q *Queries) GetTodoByIdWithListId(ctx context.Context, arg GetTodoByIdWith
 		&i.UpdatedAt,
 		&i.CompleteBefore,
 		&i.CompletedAt,
+		&i.StateID,
 	)
 	return i, err
 }
 
 const getTodosAccessibleByUserId = `-- name: GetTodosAccessibleByUserId :many
-SELECT t.id, t.parent_id, t.list_id, t.user_id, t.title, t.description, t.completed, t.created_at, t.updated_at, t.complete_before, t.completed_at FROM todos t
+SELECT t.id, t.parent_id, t.list_id, t.user_id, t.title, t.description, t.completed, t.created_at, t.updated_at, t.complete_before, t.completed_at, t.state_id FROM todos t
 JOIN lists l ON t.list_id = l.id
 WHERE l.user_id = $1 OR l.id IN (
     SELECT ls.list_id FROM list_shares ls WHERE ls.user_id = $1
@@ -137,6 +141,7 @@ func (q *Queries) GetTodosAccessibleByUserId(ctx context.Context, userID string)
 			&i.UpdatedAt,
 			&i.CompleteBefore,
 			&i.CompletedAt,
+			&i.StateID,
 		); err != nil {
 			return nil, err
 		}
@@ -149,7 +154,7 @@ func (q *Queries) GetTodosAccessibleByUserId(ctx context.Context, userID string)
 }
 
 const getTodosByList = `-- name: GetTodosByList :many
-SELECT id, parent_id, list_id, user_id, title, description, completed, created_at, updated_at, complete_before, completed_at FROM todos
+SELECT id, parent_id, list_id, user_id, title, description, completed, created_at, updated_at, complete_before, completed_at, state_id FROM todos
 WHERE list_id = $1
 `
 
@@ -174,6 +179,7 @@ func (q *Queries) GetTodosByList(ctx context.Context, listID string) ([]Todo, er
 			&i.UpdatedAt,
 			&i.CompleteBefore,
 			&i.CompletedAt,
+			&i.StateID,
 		); err != nil {
 			return nil, err
 		}
@@ -186,7 +192,7 @@ func (q *Queries) GetTodosByList(ctx context.Context, listID string) ([]Todo, er
 }
 
 const getTodosByListIds = `-- name: GetTodosByListIds :many
-SELECT id, parent_id, list_id, user_id, title, description, completed, created_at, updated_at, complete_before, completed_at FROM todos
+SELECT id, parent_id, list_id, user_id, title, description, completed, created_at, updated_at, complete_before, completed_at, state_id FROM todos
 WHERE list_id = ANY($1::text[])
 `
 
@@ -211,6 +217,7 @@ func (q *Queries) GetTodosByListIds(ctx context.Context, dollar_1 []string) ([]T
 			&i.UpdatedAt,
 			&i.CompleteBefore,
 			&i.CompletedAt,
+			&i.StateID,
 		); err != nil {
 			return nil, err
 		}
@@ -224,25 +231,45 @@ func (q *Queries) GetTodosByListIds(ctx context.Context, dollar_1 []string) ([]T
 
 const updateTodo = `-- name: UpdateTodo :one
 UPDATE todos
-SET title = $1, description = $2, completed = $3, complete_before = $4, updated_at = CURRENT_TIMESTAMP, completed_at = CASE WHEN $3 THEN CURRENT_TIMESTAMP ELSE NULL END
-WHERE id = $5
-RETURNING id, parent_id, list_id, user_id, title, description, completed, created_at, updated_at, complete_before, completed_at
+SET title = $1,
+    description = $2,
+    complete_before = $3,
+    state_id = $4,
+    completed = COALESCE(
+        (SELECT s.is_done FROM list_states s WHERE s.id = $4),
+        $5::boolean
+    ),
+    completed_at = CASE
+        WHEN NOT COALESCE(
+            (SELECT s.is_done FROM list_states s WHERE s.id = $4),
+            $5::boolean
+        ) THEN NULL
+        WHEN completed THEN completed_at
+        ELSE CURRENT_TIMESTAMP
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $6
+RETURNING id, parent_id, list_id, user_id, title, description, completed, created_at, updated_at, complete_before, completed_at, state_id
 `
 
 type UpdateTodoParams struct {
 	Title          string           `json:"title"`
 	Description    pgtype.Text      `json:"description"`
-	Completed      bool             `json:"completed"`
 	CompleteBefore pgtype.Timestamp `json:"complete_before"`
+	StateID        pgtype.Text      `json:"state_id"`
+	Completed      bool             `json:"completed"`
 	ID             string           `json:"id"`
 }
 
+// If the todo has a state, completed is derived from it. Otherwise the given
+// completed value is used. completed_at is kept while the todo stays completed.
 func (q *Queries) UpdateTodo(ctx context.Context, arg UpdateTodoParams) (Todo, error) {
 	row := q.db.QueryRow(ctx, updateTodo,
 		arg.Title,
 		arg.Description,
-		arg.Completed,
 		arg.CompleteBefore,
+		arg.StateID,
+		arg.Completed,
 		arg.ID,
 	)
 	var i Todo
@@ -258,6 +285,47 @@ func (q *Queries) UpdateTodo(ctx context.Context, arg UpdateTodoParams) (Todo, e
 		&i.UpdatedAt,
 		&i.CompleteBefore,
 		&i.CompletedAt,
+		&i.StateID,
+	)
+	return i, err
+}
+
+const updateTodoState = `-- name: UpdateTodoState :one
+UPDATE todos
+SET state_id = s.id,
+    completed = s.is_done,
+    completed_at = CASE
+        WHEN NOT s.is_done THEN NULL
+        WHEN todos.completed THEN todos.completed_at
+        ELSE CURRENT_TIMESTAMP
+    END,
+    updated_at = CURRENT_TIMESTAMP
+FROM list_states s
+WHERE todos.id = $1 AND s.id = $2 AND s.list_id = todos.list_id
+RETURNING todos.id, todos.parent_id, todos.list_id, todos.user_id, todos.title, todos.description, todos.completed, todos.created_at, todos.updated_at, todos.complete_before, todos.completed_at, todos.state_id
+`
+
+type UpdateTodoStateParams struct {
+	ID      string `json:"id"`
+	StateID string `json:"state_id"`
+}
+
+func (q *Queries) UpdateTodoState(ctx context.Context, arg UpdateTodoStateParams) (Todo, error) {
+	row := q.db.QueryRow(ctx, updateTodoState, arg.ID, arg.StateID)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.ParentID,
+		&i.ListID,
+		&i.UserID,
+		&i.Title,
+		&i.Description,
+		&i.Completed,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompleteBefore,
+		&i.CompletedAt,
+		&i.StateID,
 	)
 	return i, err
 }
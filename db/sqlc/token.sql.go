@@ -12,8 +12,8 @@ import (
 )
 
 const createJwtToken = `-- name: CreateJwtToken :exec
-INSERT INTO jwt_tokens (jti, user_id, family, created_at, expires_at)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO jwt_tokens (jti, user_id, family, created_at, expires_at, ip, user_agent)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 `
 
 type CreateJwtTokenParams struct {
@@ -22,6 +22,8 @@ type CreateJwtTokenParams struct {
 	Family    string           `json:"family"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
 	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	Ip        string           `json:"ip"`
+	UserAgent string           `json:"user_agent"`
 }
 
 func (q *Queries) CreateJwtToken(ctx context.Context, arg CreateJwtTokenParams) error {
@@ -31,10 +33,32 @@ func (q *Queries) CreateJwtToken(ctx context.Context, arg CreateJwtTokenParams)
 		arg.Family,
 		arg.CreatedAt,
 		arg.ExpiresAt,
+		arg.Ip,
+		arg.UserAgent,
 	)
 	return err
 }
 
+const deleteExpiredJwtTokens = `-- name: DeleteExpiredJwtTokens :execrows
+DELETE FROM jwt_tokens
+WHERE jti IN (
+    SELECT jti FROM jwt_tokens
+    WHERE expires_at < CURRENT_TIMESTAMP
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+`
+
+// Deletes at most batch_size expired tokens. Rows locked by other
+// transactions are skipped.
+func (q *Queries) DeleteExpiredJwtTokens(ctx context.Context, batchSize int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredJwtTokens, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteJwtTokenByFamily = `-- name: DeleteJwtTokenByFamily :execrows
 DELETE FROM jwt_tokens
 WHERE family = $1
@@ -48,6 +72,24 @@ func (q *Queries) DeleteJwtTokenByFamily(ctx context.Context, family string) (in
 	return result.RowsAffected(), nil
 }
 
+const deleteJwtTokenByUserIdAndFamily = `-- name: DeleteJwtTokenByUserIdAndFamily :execrows
+DELETE FROM jwt_tokens
+WHERE user_id = $1 AND family = $2
+`
+
+type DeleteJwtTokenByUserIdAndFamilyParams struct {
+	UserID string `json:"user_id"`
+	Family string `json:"family"`
+}
+
+func (q *Queries) DeleteJwtTokenByUserIdAndFamily(ctx context.Context, arg DeleteJwtTokenByUserIdAndFamilyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteJwtTokenByUserIdAndFamily, arg.UserID, arg.Family)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteJwtTokenByUserIdExcludeFamily = `-- name: DeleteJwtTokenByUserIdExcludeFamily :exec
 DELETE FROM jwt_tokens
 WHERE user_id = $1 AND family != $2
@@ -73,8 +115,33 @@ func (q *Queries) DeleteJwtTokensByUserId(ctx context.Context, userID string) er
 	return err
 }
 
+const deleteUsedJwtTokens = `-- name: DeleteUsedJwtTokens :execrows
+DELETE FROM jwt_tokens
+WHERE jti IN (
+    SELECT jti FROM jwt_tokens
+    WHERE is_used AND created_at < $1
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+`
+
+type DeleteUsedJwtTokensParams struct {
+	UsedBefore pgtype.Timestamp `json:"used_before"`
+	BatchSize  int32            `json:"batch_size"`
+}
+
+// Deletes at most batch_size used tokens created before used_before. Rows
+// locked by other transactions are skipped.
+func (q *Queries) DeleteUsedJwtTokens(ctx context.Context, arg DeleteUsedJwtTokensParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUsedJwtTokens, arg.UsedBefore, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getJwtTokenByJti = `-- name: GetJwtTokenByJti :one
-SELECT jti, family, user_id, is_used, created_at, expires_at
+SELECT jti, family, user_id, is_used, created_at, expires_at, ip, user_agent
 FROM jwt_tokens
 WHERE jti = $1
 `
@@ -89,10 +156,74 @@ func (q *Queries) GetJwtTokenByJti(ctx context.Context, jti string) (JwtToken, e
 		&i.IsUsed,
 		&i.CreatedAt,
 		&i.ExpiresAt,
+		&i.Ip,
+		&i.UserAgent,
 	)
 	return i, err
 }
 
+const getSessionsByUserId = `-- name: GetSessionsByUserId :many
+SELECT sessions.family,
+    sessions.device_name,
+    sessions.ip AS created_ip,
+    sessions.user_agent AS created_user_agent,
+    sessions.created_at,
+    sessions.last_used_at,
+    MAX(jwt_tokens.expires_at)::timestamp AS expires_at,
+    (ARRAY_AGG(jwt_tokens.ip ORDER BY jwt_tokens.created_at DESC))[1]::text AS ip,
+    (ARRAY_AGG(jwt_tokens.user_agent ORDER BY jwt_tokens.created_at DESC))[1]::text AS user_agent
+FROM sessions
+JOIN jwt_tokens ON jwt_tokens.family = sessions.family
+WHERE sessions.user_id = $1
+GROUP BY sessions.family
+HAVING BOOL_OR(NOT jwt_tokens.is_used AND jwt_tokens.expires_at > CURRENT_TIMESTAMP)
+ORDER BY sessions.last_used_at DESC
+`
+
+type GetSessionsByUserIdRow struct {
+	Family           string           `json:"family"`
+	DeviceName       string           `json:"device_name"`
+	CreatedIp        string           `json:"created_ip"`
+	CreatedUserAgent string           `json:"created_user_agent"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	LastUsedAt       pgtype.Timestamp `json:"last_used_at"`
+	ExpiresAt        pgtype.Timestamp `json:"expires_at"`
+	Ip               string           `json:"ip"`
+	UserAgent        string           `json:"user_agent"`
+}
+
+// A session is a token family that still has an unused refresh token. The
+// newest token of the family tells where the session was last used from.
+func (q *Queries) GetSessionsByUserId(ctx context.Context, userID string) ([]GetSessionsByUserIdRow, error) {
+	rows, err := q.db.Query(ctx, getSessionsByUserId, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSessionsByUserIdRow{}
+	for rows.Next() {
+		var i GetSessionsByUserIdRow
+		if err := rows.Scan(
+			&i.Family,
+			&i.DeviceName,
+			&i.CreatedIp,
+			&i.CreatedUserAgent,
+			&i.CreatedAt,
+			&i.LastUsedAt,
+			&i.ExpiresAt,
+			&i.Ip,
+			&i.UserAgent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const useJwtToken = `-- name: UseJwtToken :exec
 UPDATE jwt_tokens
 SET is_used = TRUE
@@ -11,6 +11,24 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+WHERE starts_with(LOWER(username), LOWER($1::text))
+    AND ($2::boolean IS NULL OR is_admin = $2)
+`
+
+type CountUsersParams struct {
+	UsernamePrefix string      `json:"username_prefix"`
+	IsAdmin        pgtype.Bool `json:"is_admin"`
+}
+
+func (q *Queries) CountUsers(ctx context.Context, arg CountUsersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUsers, arg.UsernamePrefix, arg.IsAdmin)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (id, username, password_hash, is_admin)
 VALUES ($1, $2, $3, $4)
@@ -98,8 +116,62 @@ func (q *Queries) GetAllUsers(ctx context.Context) ([]GetAllUsersRow, error) {
 	return items, nil
 }
 
+const getPasswordHashFormats = `-- name: GetPasswordHashFormats :many
+SELECT DISTINCT (CASE
+    WHEN starts_with(password_hash, '$argon2id$') THEN regexp_replace(password_hash, '(\$[^$]*){2}$', '')
+    ELSE left(password_hash, 7)
+END)::text AS format
+FROM users
+`
+
+// The algorithms and parameters of the stored hashes: argon2id hashes
+// without their salt and key, bcrypt hashes up to their cost.
+func (q *Queries) GetPasswordHashFormats(ctx context.Context) ([]string, error) {
+	rows, err := q.db.Query(ctx, getPasswordHashFormats)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var format string
+		if err := rows.Scan(&format); err != nil {
+			return nil, err
+		}
+		items = append(items, format)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, username, password_hash, is_admin, created_at, email, display_name, timezone, locale, email_verified_at
+FROM users
+WHERE LOWER(email) = LOWER($1)
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, lower string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByEmail, lower)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.IsAdmin,
+		&i.CreatedAt,
+		&i.Email,
+		&i.DisplayName,
+		&i.Timezone,
+		&i.Locale,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
 const getUserById = `-- name: GetUserById :one
-SELECT id, username, password_hash, is_admin, created_at
+SELECT id, username, password_hash, is_admin, created_at, email, display_name, timezone, locale, email_verified_at
 FROM users
 WHERE id = $1
 `
@@ -113,12 +185,17 @@ func (q *Queries) GetUserById(ctx context.Context, id string) (User, error) {
 		&i.PasswordHash,
 		&i.IsAdmin,
 		&i.CreatedAt,
+		&i.Email,
+		&i.DisplayName,
+		&i.Timezone,
+		&i.Locale,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, password_hash, is_admin, created_at
+SELECT id, username, password_hash, is_admin, created_at, email, display_name, timezone, locale, email_verified_at
 FROM users
 WHERE username = $1
 `
@@ -132,38 +209,155 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.PasswordHash,
 		&i.IsAdmin,
 		&i.CreatedAt,
+		&i.Email,
+		&i.DisplayName,
+		&i.Timezone,
+		&i.Locale,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
 
+const listUsers = `-- name: ListUsers :many
+SELECT id, username, is_admin, created_at, email, display_name, timezone, locale, email_verified_at,
+    (SELECT COUNT(*) FROM lists WHERE lists.user_id = users.id) AS list_count,
+    (SELECT COUNT(*) FROM sessions
+        WHERE sessions.user_id = users.id AND EXISTS (
+            SELECT 1 FROM jwt_tokens
+            WHERE jwt_tokens.family = sessions.family
+                AND NOT jwt_tokens.is_used
+                AND jwt_tokens.expires_at > CURRENT_TIMESTAMP
+        )
+    ) AS session_count
+FROM users
+WHERE starts_with(LOWER(username), LOWER($1::text))
+    AND ($2::boolean IS NULL OR is_admin = $2)
+ORDER BY
+    CASE WHEN $3::text = 'username' AND NOT $4::boolean THEN username END,
+    CASE WHEN $3::text = 'username' AND $4::boolean THEN username END DESC,
+    CASE WHEN $3::text = 'created_at' AND NOT $4::boolean THEN created_at END,
+    CASE WHEN $3::text = 'created_at' AND $4::boolean THEN created_at END DESC,
+    id
+LIMIT $5 OFFSET $6
+`
+
+type ListUsersParams struct {
+	UsernamePrefix string      `json:"username_prefix"`
+	IsAdmin        pgtype.Bool `json:"is_admin"`
+	SortBy         string      `json:"sort_by"`
+	SortDesc       bool        `json:"sort_desc"`
+	RowLimit       int32       `json:"row_limit"`
+	RowOffset      int32       `json:"row_offset"`
+}
+
+type ListUsersRow struct {
+	ID              string           `json:"id"`
+	Username        string           `json:"username"`
+	IsAdmin         bool             `json:"is_admin"`
+	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	Email           pgtype.Text      `json:"email"`
+	DisplayName     string           `json:"display_name"`
+	Timezone        string           `json:"timezone"`
+	Locale          string           `json:"locale"`
+	EmailVerifiedAt pgtype.Timestamp `json:"email_verified_at"`
+	ListCount       int64            `json:"list_count"`
+	SessionCount    int64            `json:"session_count"`
+}
+
+// Users whose username starts with username_prefix, sorted by sort_by
+// ('username' or 'created_at'). Active sessions have a usable refresh token.
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error) {
+	rows, err := q.db.Query(ctx, listUsers,
+		arg.UsernamePrefix,
+		arg.IsAdmin,
+		arg.SortBy,
+		arg.SortDesc,
+		arg.RowLimit,
+		arg.RowOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUsersRow{}
+	for rows.Next() {
+		var i ListUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.IsAdmin,
+			&i.CreatedAt,
+			&i.Email,
+			&i.DisplayName,
+			&i.Timezone,
+			&i.Locale,
+			&i.EmailVerifiedAt,
+			&i.ListCount,
+			&i.SessionCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
-SET username = $2, is_admin = $3
+SET username = $2, is_admin = $3, email = $4,
+    email_verified_at = CASE WHEN email IS NOT DISTINCT FROM $4 THEN email_verified_at END,
+    display_name = $5, timezone = $6, locale = $7
 WHERE id = $1
-RETURNING id, username, is_admin, created_at
+RETURNING id, username, is_admin, created_at, email, display_name, timezone, locale, email_verified_at
 `
 
 type UpdateUserParams struct {
-	ID       string `json:"id"`
-	Username string `json:"username"`
-	IsAdmin  bool   `json:"is_admin"`
+	ID          string      `json:"id"`
+	Username    string      `json:"username"`
+	IsAdmin     bool        `json:"is_admin"`
+	Email       pgtype.Text `json:"email"`
+	DisplayName string      `json:"display_name"`
+	Timezone    string      `json:"timezone"`
+	Locale      string      `json:"locale"`
 }
 
 type UpdateUserRow struct {
-	ID        string           `json:"id"`
-	Username  string           `json:"username"`
-	IsAdmin   bool             `json:"is_admin"`
-	CreatedAt pgtype.Timestamp `json:"created_at"`
+	ID              string           `json:"id"`
+	Username        string           `json:"username"`
+	IsAdmin         bool             `json:"is_admin"`
+	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	Email           pgtype.Text      `json:"email"`
+	DisplayName     string           `json:"display_name"`
+	Timezone        string           `json:"timezone"`
+	Locale          string           `json:"locale"`
+	EmailVerifiedAt pgtype.Timestamp `json:"email_verified_at"`
 }
 
+// Changing the email forgets that the old one was verified.
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateUserRow, error) {
-	row := q.db.QueryRow(ctx, updateUser, arg.ID, arg.Username, arg.IsAdmin)
+	row := q.db.QueryRow(ctx, updateUser,
+		arg.ID,
+		arg.Username,
+		arg.IsAdmin,
+		arg.Email,
+		arg.DisplayName,
+		arg.Timezone,
+		arg.Locale,
+	)
 	var i UpdateUserRow
 	err := row.Scan(
 		&i.ID,
 		&i.Username,
 		&i.IsAdmin,
 		&i.CreatedAt,
+		&i.Email,
+		&i.DisplayName,
+		&i.Timezone,
+		&i.Locale,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
@@ -183,3 +377,20 @@ func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPassword
 	_, err := q.db.Exec(ctx, updateUserPassword, arg.ID, arg.PasswordHash)
 	return err
 }
+
+const verifyUserEmail = `-- name: VerifyUserEmail :exec
+UPDATE users
+SET email = $2, email_verified_at = $3
+WHERE id = $1
+`
+
+type VerifyUserEmailParams struct {
+	ID              string           `json:"id"`
+	Email           pgtype.Text      `json:"email"`
+	EmailVerifiedAt pgtype.Timestamp `json:"email_verified_at"`
+}
+
+func (q *Queries) VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) error {
+	_, err := q.db.Exec(ctx, verifyUserEmail, arg.ID, arg.Email, arg.EmailVerifiedAt)
+	return err
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"errors"
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/loginguard"
+	"go-todo/util/mycontext"
+	"go-todo/util/securetoken"
+	"go-todo/util/validate"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Sets a new password with a token from RequestPasswordReset. Ends all of the
+// user's sessions.
+func (controller *AuthController) ConfirmPasswordReset(ctx *gin.Context) {
+	if controller.mailer == nil {
+		ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	var payload *schemas.ConfirmPasswordReset
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	}
+
+	invalidToken := func(err error) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreMedium,
+			logging.SecurityEventPasswordResetTokenInvalid,
+			ctx.FullPath(),
+			"",
+			ctx.ClientIP(),
+		)
+		ctx.Error(
+			gterrors.NewGtAuthError(gterrors.GtAuthErrorReasonTokenInvalid, err),
+		).SetType(gterrors.GetGinErrorType())
+	}
+
+	tokenArgs := &db.GetValidPasswordResetTokenParams{
+		TokenHash: securetoken.Hash(payload.Token),
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	}
+	resetToken, err := controller.db.GetValidPasswordResetToken(ctx, *tokenArgs)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			invalidToken(errors.New("reset token is unknown, used or expired"))
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get reset token from db", file, line, err, ctx)
+		return
+	}
+
+	// Should only fail if something is wrong in the server
+	user, err := controller.db.GetUserById(ctx, resetToken.UserID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get user from db", file, line, err, ctx)
+		return
+	}
+
+	// Checked before using up the token so the user can try another password.
+	if err := validate.Password(payload.NewPassword, user.Username); err != nil {
+		ctx.Error(err).SetType(gin.ErrorTypePublic)
+		return
+	}
+	if ok := controller.checkPasswordReuse(user, payload.NewPassword, ctx); !ok {
+		return
+	}
+
+	rows, err := controller.db.UsePasswordResetToken(ctx, resetToken.ID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to use reset token", file, line, err, ctx)
+		return
+	} else if rows == 0 {
+		invalidToken(errors.New("reset token has already been used"))
+		return
+	}
+
+	if ok := controller.setPassword(user, payload.NewPassword, ctx); !ok {
+		return
+	}
+	if err := controller.db.DeleteJwtTokensByUserId(ctx, user.ID); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to delete refresh jwts", file, line, err, ctx)
+		return
+	}
+	if err := controller.db.DeletePasswordResetTokensByUserId(ctx, user.ID); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to delete reset tokens", file, line, err, ctx)
+		return
+	}
+	// Whoever got locked out by guessing doesn't know the new password.
+	loginguard.Unlock(user.Username)
+
+	logging.LogSessionEvent(
+		true,
+		ctx.FullPath(),
+		user.Username,
+		logging.SessionEventTypePasswordReset,
+		ctx.ClientIP(),
+	)
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"errors"
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/mycontext"
+	"go-todo/util/totp"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const recoveryCodeCount = 10
+
+// Enables 2FA once the user proves they have the secret from EnrollTotp.
+// Responds with recovery codes, which are shown only this once.
+func (controller *AuthController) ConfirmTotp(ctx *gin.Context) {
+	userID, _, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	var payload *schemas.ConfirmTotp
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	}
+
+	// Should only fail if something is wrong in the server
+	user, err := controller.db.GetUserById(ctx, userID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get user from db", file, line, err, ctx)
+		return
+	}
+
+	userTotp, err := controller.db.GetUserTotp(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get totp from db", file, line, err, ctx)
+		return
+	} else if userTotp.Confirmed {
+		ctx.Error(gterrors.ErrUniqueViolation).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	step, ok := totp.Validate(userTotp.Secret, payload.Code, time.Now())
+	if !ok {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventTotpFailed,
+			ctx.FullPath(),
+			user.Username,
+			ctx.ClientIP(),
+		)
+		ctx.Error(gterrors.NewGtValueError(payload.Code, "invalid code")).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	args := &db.ConfirmUserTotpParams{
+		UserID:       user.ID,
+		LastUsedStep: step,
+	}
+	if err := controller.db.ConfirmUserTotp(ctx, *args); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to confirm totp", file, line, err, ctx)
+		return
+	}
+
+	// Codes left over from an earlier enrollment must not work anymore.
+	if err := controller.db.DeleteRecoveryCodesByUserId(ctx, user.ID); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to delete recovery codes", file, line, err, ctx)
+		return
+	}
+	recoveryCodes, err := totp.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to generate recovery codes", file, line, err, ctx)
+		return
+	}
+	for _, recoveryCode := range recoveryCodes {
+		codeArgs := &db.CreateRecoveryCodeParams{
+			ID:       uuid.NewString(),
+			UserID:   user.ID,
+			CodeHash: totp.HashRecoveryCode(recoveryCode),
+		}
+		if err := controller.db.CreateRecoveryCode(ctx, *codeArgs); err != nil {
+			_, file, line, _ := runtime.Caller(0)
+			mycontext.CtxAddGtInternalError("failed to save recovery code", file, line, err, ctx)
+			return
+		}
+	}
+
+	logging.LogSessionEvent(
+		true,
+		ctx.FullPath(),
+		user.Username,
+		logging.SessionEventTypeTotpEnable,
+		ctx.ClientIP(),
+	)
+	ctx.JSON(http.StatusOK, gin.H{
+		"status":         "ok",
+		"recovery_codes": recoveryCodes,
+	})
+}
@@ -2,21 +2,45 @@ package auth
 
 import (
 	"context"
+	"errors"
 	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
 	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/authcookie"
+	"go-todo/util/database"
 	"go-todo/util/jwt"
+	"go-todo/util/loginguard"
+	"go-todo/util/mailer"
 	"go-todo/util/mycontext"
+	"go-todo/util/oidc"
+	"go-todo/util/totp"
+	"net/http"
+	"runtime"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type AuthController struct {
-	db  *db.Queries
-	ctx context.Context
+	db *db.Queries
+	// Nil when mail is disabled, which disables password resets.
+	mailer mailer.Mailer
+	// Nil when OIDC login is disabled.
+	oidc *oidc.Provider
+	ctx  context.Context
 }
 
-func NewController(db *db.Queries, ctx context.Context) *AuthController {
-	return &AuthController{db: db, ctx: ctx}
+func NewController(
+	db *db.Queries,
+	mailer mailer.Mailer,
+	oidc *oidc.Provider,
+	ctx context.Context,
+) *AuthController {
+	return &AuthController{db: db, mailer: mailer, oidc: oidc, ctx: ctx}
 }
 
 func logTokenEventUse(success bool, token *jwt.GtClaims, c *gin.Context) {
@@ -55,6 +79,7 @@ func generateTokens(family string, user db.User) (
 		user.Username,
 		user.ID,
 		user.IsAdmin,
+		refreshClaims.Family,
 	)
 	if err != nil {
 
@@ -63,6 +88,266 @@ func generateTokens(family string, user db.User) (
 	return
 }
 
+// Returns the requesting user and the user whose sessions are handled. Admins
+// can pass user_id as a query parameter to handle the sessions of any user.
+func (controller *AuthController) getSessionUsers(ctx *gin.Context) (reqUser *db.User, target *db.User, ok bool) {
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+	reqUser, err = database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	targetID := ctx.DefaultQuery("user_id", reqUser.ID)
+	if targetID == reqUser.ID {
+		return reqUser, reqUser, true
+	}
+	if !mycontext.HasAdminRights(reqUser, ctx) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreMedium,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			targetID,
+			reqUser.Username,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gin.ErrorTypePublic)
+		return
+	}
+	user, err := controller.db.GetUserById(ctx, targetID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get user from db", file, line, err, ctx)
+		return
+	}
+	return reqUser, &user, true
+}
+
+// Issues a new access and refresh token pair for the user, saves the refresh
+// token and responds with both tokens.
+// With 2FA enabled the first factor only gets the user a challenge token
+// that is exchanged for a session in LoginTotp. Responds with the challenge
+// and returns false if the user has confirmed 2FA, or if it failed.
+func (controller *AuthController) passTotp(user db.User, ctx *gin.Context) bool {
+	userTotp, err := controller.db.GetUserTotp(ctx, user.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get totp from db", file, line, err, ctx)
+		return false
+	} else if err != nil || !userTotp.Confirmed {
+		return true
+	}
+
+	challengeToken, challengeClaims, err := jwt.GenerateChallengeJwt(user.Username, user.ID, user.IsAdmin)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		failedToGenerateJwtError(err, file, line, ctx)
+		return false
+	}
+	logTokenCreations([]*jwt.GtClaims{challengeClaims}, ctx)
+	ctx.JSON(http.StatusOK, gin.H{
+		"status":          "totp-required",
+		"challenge_token": challengeToken,
+	})
+	return false
+}
+
+func (controller *AuthController) startSession(user db.User, deviceName string, useCookies bool, ctx *gin.Context) {
+	if useCookies && !authcookie.Enabled() {
+		ctx.Error(
+			gterrors.NewGtValueError("use_cookies", "cookie authentication is disabled"),
+		).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	refreshToken, refreshClaims, accessToken, accessClaims, err := generateTokens(
+		"",
+		user,
+	)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		failedToGenerateJwtError(err, file, line, ctx)
+		return
+	}
+
+	args := &db.CreateJwtTokenParams{
+		Jti:       refreshClaims.ID,
+		UserID:    refreshClaims.Subject,
+		Family:    refreshClaims.Family,
+		CreatedAt: pgtype.Timestamp{Time: refreshClaims.IssuedAt.Time, Valid: true},
+		ExpiresAt: pgtype.Timestamp{Time: refreshClaims.ExpiresAt.Time, Valid: true},
+		Ip:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+
+	if err := controller.db.CreateJwtToken(ctx, *args); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		failedToSaveJwtToDbError(err, file, line, ctx)
+		return
+	}
+	if ok := controller.saveSession(refreshClaims.Family, user, deviceName, ctx); !ok {
+		return
+	}
+
+	logging.LogSessionEvent(
+		true,
+		ctx.FullPath(),
+		user.Username,
+		logging.SessionEventTypeLogin,
+		ctx.ClientIP(),
+	)
+	logTokenCreations([]*jwt.GtClaims{refreshClaims, accessClaims}, ctx)
+	respondWithTokens(refreshToken, refreshClaims, accessToken, accessClaims, useCookies, ctx)
+}
+
+// Responds with the tokens of a session. With cookies the refresh token, and
+// in cookie mode all also the access token, is only set as a cookie so
+// scripts on the page can't read it.
+func respondWithTokens(
+	refreshToken string,
+	refreshClaims *jwt.GtClaims,
+	accessToken string,
+	accessClaims *jwt.GtClaims,
+	useCookies bool,
+	ctx *gin.Context,
+) {
+	if !useCookies {
+		ctx.JSON(http.StatusOK, gin.H{
+			"status":        "ok",
+			"access_token":  accessToken,
+			"refresh_token": refreshToken,
+		})
+		return
+	}
+
+	err := authcookie.SetSession(
+		ctx,
+		refreshToken,
+		refreshClaims.ExpiresAt.Time,
+		accessToken,
+		accessClaims.ExpiresAt.Time,
+	)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to set session cookies", file, line, err, ctx)
+		return
+	}
+	response := gin.H{"status": "ok"}
+	if authcookie.AccessTokenInBody() {
+		response["access_token"] = accessToken
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Returns the refresh token from the body, or from the refresh cookie if the
+// body has none. fromCookie tells whether the client uses cookies.
+func bindRefreshToken(ctx *gin.Context) (refreshToken string, fromCookie bool, ok bool) {
+	var payload schemas.Refresh
+	// Cookie clients may send no body at all.
+	if ctx.Request.ContentLength != 0 {
+		if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+			return "", false, false
+		}
+	}
+	if payload.RefreshToken != "" {
+		return payload.RefreshToken, false, true
+	}
+	if refreshToken := authcookie.RefreshToken(ctx); refreshToken != "" {
+		return refreshToken, true, true
+	}
+	ctx.Error(
+		gterrors.NewGtAuthError(
+			gterrors.GtAuthErrorReasonTokenInvalid,
+			errors.New("missing refresh token"),
+		),
+	).SetType(gterrors.GetGinErrorType())
+	return "", false, false
+}
+
+// Responds with 429 if the username or the IP has failed to log in too often
+// and must wait before trying again.
+func loginBlocked(username string, ctx *gin.Context) bool {
+	wait := loginguard.Check(username, ctx.ClientIP())
+	if wait <= 0 {
+		return false
+	}
+	logging.LogSecurityEvent(
+		logging.SecurityScoreLow,
+		logging.SecurityEventLoginThrottled,
+		ctx.FullPath(),
+		username,
+		ctx.ClientIP(),
+	)
+	ctx.Error(
+		gterrors.NewGtTooManyRequestsError(wait, errors.New("too many failed login attempts")),
+	).SetType(gterrors.GetGinErrorType())
+	return true
+}
+
+// Records a failed login and tells the client when it may try again.
+func loginFailed(username string, ctx *gin.Context) {
+	retryAfter, lockedOut := loginguard.Fail(username, ctx.ClientIP())
+	if lockedOut {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreHigh,
+			logging.SecurityEventLoginLockout,
+			ctx.FullPath(),
+			username,
+			ctx.ClientIP(),
+		)
+	}
+	if retryAfter > 0 {
+		ctx.Header("Retry-After", strconv.Itoa(gterrors.RetryAfterSeconds(retryAfter)))
+	}
+}
+
+// Maps the reason a jwt couldn't be decoded to the reason given to the client.
+func jwtDecodeErrorReason(jwtErr *jwt.JwtDecodeError) gterrors.GtAuthErrorReason {
+	switch jwtErr.Reason {
+	case jwt.JwtErrorReasonExpired:
+		return gterrors.GtAuthErrorReasonExpired
+	case jwt.JwtErrorReasonInvalidSignature:
+		return gterrors.GtAuthErrorReasonInvalidSignature
+	case jwt.JwtErrorReasonTokenMalformed:
+		return gterrors.GtAuthErrorReasonTokenInvalid
+	}
+	return gterrors.GtAuthErrorReasonInternalError
+}
+
+// Checks a second factor code, which is either a TOTP code or an unused
+// recovery code. Both can only be used once.
+func (controller *AuthController) verifySecondFactor(userTotp db.UserTotp, code string, ctx *gin.Context) (bool, error) {
+	if step, ok := totp.Validate(userTotp.Secret, code, time.Now()); ok {
+		args := &db.UseUserTotpStepParams{
+			UserID:       userTotp.UserID,
+			LastUsedStep: step,
+		}
+		rows, err := controller.db.UseUserTotpStep(ctx, *args)
+		return rows == 1, err
+	}
+
+	args := &db.UseRecoveryCodeParams{
+		UserID:   userTotp.UserID,
+		CodeHash: totp.HashRecoveryCode(code),
+	}
+	rows, err := controller.db.UseRecoveryCode(ctx, *args)
+	return rows == 1, err
+}
+
 func failedToGenerateJwtError(err error, file string, line int, c *gin.Context) {
 	mycontext.CtxAddGtInternalError("failed to generate jwt", file, line, err, c)
 }
@@ -70,5 +355,3 @@ func failedToGenerateJwtError(err error, file string, line int, c *gin.Context)
 func failedToSaveJwtToDbError(err error, file string, line int, c *gin.Context) {
 	mycontext.CtxAddGtInternalError("failed to save jwt to db", file, line, err, c)
 }
-
-// TODO Add ResetPassword (Requires email to be implemented)
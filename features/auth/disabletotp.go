@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"errors"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/mycontext"
+	"go-todo/util/passwd"
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Disables 2FA and removes the recovery codes. Requires the password so a
+// stolen access token alone can't be used to turn 2FA off.
+func (controller *AuthController) DisableTotp(ctx *gin.Context) {
+	userID, _, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	var payload *schemas.DisableTotp
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	}
+
+	// Should only fail if something is wrong in the server
+	user, err := controller.db.GetUserById(ctx, userID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get user from db", file, line, err, ctx)
+		return
+	}
+
+	if !passwd.Compare(payload.Password, user.PasswordHash) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventFailedLogin,
+			ctx.FullPath(),
+			user.Username,
+			ctx.ClientIP(),
+		)
+		ctx.Error(
+			gterrors.NewGtAuthError(
+				gterrors.GtAuthErrorReasonInvalidCredentials,
+				errors.New("provided credentials are incorrect"),
+			),
+		).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	if err := controller.db.DeleteUserTotp(ctx, user.ID); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to delete totp", file, line, err, ctx)
+		return
+	}
+	if err := controller.db.DeleteRecoveryCodesByUserId(ctx, user.ID); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to delete recovery codes", file, line, err, ctx)
+		return
+	}
+
+	logging.LogSessionEvent(
+		true,
+		ctx.FullPath(),
+		user.Username,
+		logging.SessionEventTypeTotpDisable,
+		ctx.ClientIP(),
+	)
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
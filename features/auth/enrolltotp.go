@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"errors"
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/util/config"
+	"go-todo/util/mycontext"
+	"go-todo/util/totp"
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Starts 2FA enrollment by generating a new secret. 2FA is enabled only after
+// a code from the secret has been given to ConfirmTotp.
+func (controller *AuthController) EnrollTotp(ctx *gin.Context) {
+	userID, _, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	// Should only fail if something is wrong in the server
+	user, err := controller.db.GetUserById(ctx, userID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get user from db", file, line, err, ctx)
+		return
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get config", file, line, err, ctx)
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to generate totp secret", file, line, err, ctx)
+		return
+	}
+
+	args := &db.UpsertUserTotpParams{
+		UserID: user.ID,
+		Secret: secret,
+	}
+	// No rows are returned when 2FA is already enabled.
+	if _, err := controller.db.UpsertUserTotp(ctx, *args); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrUniqueViolation).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to save totp secret", file, line, err, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+		"secret": secret,
+		"uri":    totp.URI(cfg.TotpIssuer, user.Username, secret),
+	})
+}
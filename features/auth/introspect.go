@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/config"
+	"go-todo/util/jwt"
+	"go-todo/util/mycontext"
+	"go-todo/util/pat"
+	"go-todo/util/revocation"
+	"net/http"
+	"runtime"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Tells other services whether an access or personal access token is active
+// and what it contains (RFC 7662). Callers authenticate with a client from
+// INTROSPECTION_CLIENTS.
+func (controller *AuthController) Introspect(ctx *gin.Context) {
+	cfg, err := config.Get()
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get config", file, line, err, ctx)
+		return
+	}
+
+	clientID, clientSecret, ok := ctx.Request.BasicAuth()
+	if !ok {
+		clientID = ctx.PostForm("client_id")
+		clientSecret = ctx.PostForm("client_secret")
+	}
+	if !validIntrospectionClient(cfg.IntrospectionClients, clientID, clientSecret) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreMedium,
+			logging.SecurityEventIntrospectionClientInvalid,
+			ctx.FullPath(),
+			clientID,
+			ctx.ClientIP(),
+		)
+		ctx.Header("WWW-Authenticate", `Basic realm="go-todo"`)
+		ctx.Error(
+			gterrors.NewGtAuthError(
+				gterrors.GtAuthErrorReasonInvalidCredentials,
+				errors.New("invalid client credentials"),
+			),
+		).SetType(gterrors.GetGinErrorType())
+		return
+	}
+
+	var payload schemas.Introspect
+	if ok := mycontext.ShouldBindForm(&payload, ctx); !ok {
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	// Why a token isn't active isn't told to the client.
+	inactive := func(claims *jwt.GtClaims) {
+		logging.LogTokenEvent(false, ctx.FullPath(), logging.TokenEventTypeIntrospect, ctx.ClientIP(), claims)
+		ctx.JSON(http.StatusOK, schemas.IntrospectResponse{Active: false})
+	}
+
+	if pat.IsPersonalAccessToken(payload.Token) {
+		controller.introspectPersonalToken(payload.Token, inactive, ctx)
+		return
+	}
+
+	claims, err := jwt.DecodeAccessToken(payload.Token)
+	if err != nil {
+		var jwtErr *jwt.JwtDecodeError
+		if errors.As(err, &jwtErr) {
+			inactive(jwtErr.Claims)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to decode token", file, line, err, ctx)
+		return
+	}
+
+	revoked, err := revocation.IsRevoked(ctx, claims)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to check token revocation", file, line, err, ctx)
+		return
+	} else if revoked {
+		inactive(claims)
+		return
+	}
+
+	if _, err := controller.db.GetUserById(ctx, claims.Subject); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			inactive(claims)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get user from db", file, line, err, ctx)
+		return
+	}
+
+	response := schemas.IntrospectResponse{
+		Active:    true,
+		TokenType: "Bearer",
+		Subject:   claims.Subject,
+		Username:  claims.Username,
+		IsAdmin:   &claims.IsAdmin,
+		Family:    claims.Family,
+		Jti:       claims.ID,
+		Issuer:    claims.Issuer,
+		IssuedAt:  claims.IssuedAt.Unix(),
+		ExpiresAt: claims.ExpiresAt.Unix(),
+	}
+	if claims.Act != nil {
+		response.Act = &schemas.IntrospectActor{
+			Subject:  claims.Act.Subject,
+			Username: claims.Act.Username,
+		}
+	}
+	logging.LogTokenEvent(true, ctx.FullPath(), logging.TokenEventTypeIntrospect, ctx.ClientIP(), claims)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Personal access tokens are active until they expire or are deleted, and
+// never grant admin rights.
+func (controller *AuthController) introspectPersonalToken(token string, inactive func(*jwt.GtClaims), ctx *gin.Context) {
+	personalToken, err := pat.Authenticate(ctx, token)
+	if err != nil {
+		if errors.Is(err, pat.ErrTokenInvalid) {
+			inactive(nil)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to authenticate personal access token", file, line, err, ctx)
+		return
+	}
+
+	isAdmin := false
+	response := schemas.IntrospectResponse{
+		Active:    true,
+		TokenType: "Bearer",
+		Subject:   personalToken.UserID,
+		Username:  personalToken.Username,
+		IsAdmin:   &isAdmin,
+		Scope:     strings.Join(personalToken.Scopes, " "),
+		Jti:       personalToken.ID,
+		IssuedAt:  personalToken.CreatedAt.Unix(),
+	}
+	if !personalToken.ExpiresAt.IsZero() {
+		response.ExpiresAt = personalToken.ExpiresAt.Unix()
+	}
+	logging.LogTokenEvent(true, ctx.FullPath(), logging.TokenEventTypeIntrospect, ctx.ClientIP(), nil)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Checks the credentials against clients configured as comma separated
+// id:secret pairs.
+func validIntrospectionClient(clients string, clientID string, clientSecret string) bool {
+	if clientID == "" || clientSecret == "" {
+		return false
+	}
+	for _, client := range strings.Split(clients, ",") {
+		id, secret, found := strings.Cut(strings.TrimSpace(client), ":")
+		if !found || id != clientID || secret == "" {
+			continue
+		}
+		return subtle.ConstantTimeCompare([]byte(secret), []byte(clientSecret)) == 1
+	}
+	return false
+}
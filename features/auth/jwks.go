@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"fmt"
+	"go-todo/util/jwt"
+	"go-todo/util/mycontext"
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Publishes the public keys access tokens can be verified with. The list is
+// empty when tokens are signed with a shared secret.
+func (controller *AuthController) Jwks(ctx *gin.Context) {
+	keys, err := jwt.PublicJwks()
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to load signing keys", file, line, err, ctx)
+		return
+	}
+
+	ctx.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(jwt.JwksMaxAge.Seconds())))
+	ctx.JSON(http.StatusOK, gin.H{"keys": keys})
+}
@@ -2,20 +2,16 @@ package auth
 
 import (
 	"errors"
-	db "go-todo/db/sqlc"
 	"go-todo/gterrors"
 	"go-todo/logging"
 	"go-todo/schemas"
-	"go-todo/util/jwt"
+	"go-todo/util/authn"
+	"go-todo/util/loginguard"
 	"go-todo/util/mycontext"
-	"go-todo/util/passwd"
 	"go-todo/util/validate"
-	"net/http"
 	"runtime"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func (controller *AuthController) Login(ctx *gin.Context) {
@@ -23,6 +19,9 @@ func (controller *AuthController) Login(ctx *gin.Context) {
 	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
 		return
 	}
+	if ok := validDeviceName(payload.DeviceName, ctx); !ok {
+		return
+	}
 
 	username := payload.Username
 	password := payload.Password
@@ -41,9 +40,14 @@ func (controller *AuthController) Login(ctx *gin.Context) {
 		return
 	}
 
-	user, err := controller.db.GetUserByUsername(ctx, username)
+	if loginBlocked(username, ctx) {
+		return
+	}
+
+	identity, err := authn.Authenticate(ctx, username, password)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, authn.ErrUnknownUser) {
+			loginFailed(username, ctx)
 			logging.LogSecurityEvent(
 				logging.SecurityScoreLow,
 				logging.SecurityEventLoginToUnknownUsername,
@@ -61,71 +65,60 @@ func (controller *AuthController) Login(ctx *gin.Context) {
 			return
 		}
 
+		if errors.Is(err, authn.ErrInvalidCredentials) {
+			loginFailed(username, ctx)
+			logging.LogSecurityEvent(
+				logging.SecurityScoreLow,
+				logging.SecurityEventFailedLogin,
+				ctx.FullPath(),
+				username,
+				ctx.ClientIP(),
+			)
+			logging.LogSessionEvent(
+				false,
+				ctx.FullPath(),
+				username,
+				logging.SessionEventTypeLogin,
+				ctx.ClientIP(),
+			)
+
+			ctx.Error(
+				gterrors.NewGtAuthError(
+					gterrors.GtAuthErrorReasonInvalidCredentials,
+					errors.New("password verification failed"),
+				),
+			).SetType(gterrors.GetGinErrorType())
+			return
+		}
+
 		_, file, line, _ := runtime.Caller(0)
-		mycontext.CtxAddGtInternalError("failed to get username from db", file, line, err, ctx)
+		mycontext.CtxAddGtInternalError("failed to authenticate user", file, line, err, ctx)
 		return
 	}
 
-	if pwdCorrect := passwd.Compare(password, user.PasswordHash); !pwdCorrect {
-		logging.LogSecurityEvent(
-			logging.SecurityScoreLow,
-			logging.SecurityEventFailedLogin,
-			ctx.FullPath(),
-			username,
-			ctx.ClientIP(),
-		)
-		logging.LogSessionEvent(
-			false,
-			ctx.FullPath(),
-			user.Username,
-			logging.SessionEventTypeLogin,
-			ctx.ClientIP(),
-		)
-
+	user, ok := controller.getIdentityUser(identity, ctx)
+	if !ok {
+		return
+	}
+	if user == nil {
+		loginFailed(username, ctx)
 		ctx.Error(
 			gterrors.NewGtAuthError(
 				gterrors.GtAuthErrorReasonInvalidCredentials,
-				errors.New("password verification failed"),
+				errors.New("no user is linked to the identity"),
 			),
 		).SetType(gterrors.GetGinErrorType())
 		return
 	}
-
-	refreshToken, refreshClaims, accessToken, accessClaims, err := generateTokens(
-		"",
-		user,
-	)
-	if err != nil {
-		_, file, line, _ := runtime.Caller(0)
-		failedToGenerateJwtError(err, file, line, ctx)
-		return
-	}
-
-	args := &db.CreateJwtTokenParams{
-		Jti:       refreshClaims.ID,
-		UserID:    refreshClaims.Subject,
-		Family:    refreshClaims.Family,
-		CreatedAt: pgtype.Timestamp{Time: refreshClaims.IssuedAt.Time, Valid: true},
-		ExpiresAt: pgtype.Timestamp{Time: refreshClaims.ExpiresAt.Time, Valid: true},
+	// Only local passwords are hashed here.
+	if identity.Provider == authn.ProviderLocal {
+		controller.upgradePasswordHash(*user, password, ctx)
 	}
 
-	if err := controller.db.CreateJwtToken(ctx, *args); err != nil {
-		_, file, line, _ := runtime.Caller(0)
-		failedToSaveJwtToDbError(err, file, line, ctx)
+	if ok := controller.passTotp(*user, ctx); !ok {
 		return
 	}
 
-	logging.LogSessionEvent(
-		true,
-		ctx.FullPath(),
-		user.Username,
-		logging.SessionEventTypeLogin,
-		ctx.ClientIP(),
-	)
-	logTokenCreations([]*jwt.GtClaims{refreshClaims, accessClaims}, ctx)
-	ctx.JSON(http.StatusOK, gin.H{
-		"status":        "ok",
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
-	})
+	loginguard.Succeed(user.Username)
+	controller.startSession(*user, payload.DeviceName, payload.UseCookies, ctx)
 }
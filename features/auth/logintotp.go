@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"errors"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/jwt"
+	"go-todo/util/loginguard"
+	"go-todo/util/mycontext"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Second step of the login when the user has 2FA enabled. Exchanges the
+// challenge token from Login and a TOTP or recovery code for a session.
+func (controller *AuthController) LoginTotp(ctx *gin.Context) {
+	var payload *schemas.LoginTotp
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	}
+	if ok := validDeviceName(payload.DeviceName, ctx); !ok {
+		return
+	}
+
+	claims, err := jwt.DecodeChallengeToken(payload.ChallengeToken)
+	if err != nil {
+		logTokenEventUse(false, claims, ctx)
+		var jwtErr *jwt.JwtDecodeError
+		if errors.As(err, &jwtErr) {
+			ctx.Error(
+				gterrors.NewGtAuthError(jwtDecodeErrorReason(jwtErr), err),
+			).SetType(gterrors.GetGinErrorType())
+			return
+		}
+		// Should never get to here
+		ctx.Error(gterrors.ErrShouldNotHappen)
+		return
+	}
+
+	user, err := controller.db.GetUserById(ctx, claims.Subject)
+	if err != nil {
+		logTokenEventUse(false, claims, ctx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			logging.LogSecurityEvent(
+				logging.SecurityScoreLow,
+				logging.SecurityEventJwtUserUnknown,
+				ctx.FullPath(),
+				claims.Username,
+				ctx.ClientIP(),
+			)
+			ctx.Error(
+				gterrors.NewGtAuthError(gterrors.GtAuthErrorReasonJwtUserNotFound, err),
+			).SetType(gterrors.GetGinErrorType())
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get user from db", file, line, err, ctx)
+		return
+	}
+
+	if loginBlocked(user.Username, ctx) {
+		logTokenEventUse(false, claims, ctx)
+		return
+	}
+
+	userTotp, err := controller.db.GetUserTotp(ctx, user.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		logTokenEventUse(false, claims, ctx)
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get totp from db", file, line, err, ctx)
+		return
+	}
+
+	// 2FA might have been disabled after the challenge was issued.
+	ok := false
+	if err == nil && userTotp.Confirmed {
+		ok, err = controller.verifySecondFactor(userTotp, payload.Code, ctx)
+		if err != nil {
+			logTokenEventUse(false, claims, ctx)
+			_, file, line, _ := runtime.Caller(0)
+			mycontext.CtxAddGtInternalError("failed to verify totp code", file, line, err, ctx)
+			return
+		}
+	}
+	if !ok {
+		logTokenEventUse(false, claims, ctx)
+		loginFailed(user.Username, ctx)
+		logging.LogSecurityEvent(
+			logging.SecurityScoreMedium,
+			logging.SecurityEventTotpFailed,
+			ctx.FullPath(),
+			user.Username,
+			ctx.ClientIP(),
+		)
+		logging.LogSessionEvent(
+			false,
+			ctx.FullPath(),
+			user.Username,
+			logging.SessionEventTypeLogin,
+			ctx.ClientIP(),
+		)
+		ctx.Error(
+			gterrors.NewGtAuthError(
+				gterrors.GtAuthErrorReasonInvalidCredentials,
+				errors.New("totp verification failed"),
+			),
+		).SetType(gterrors.GetGinErrorType())
+		return
+	}
+
+	logTokenEventUse(true, claims, ctx)
+	loginguard.Succeed(user.Username)
+	controller.startSession(user, payload.DeviceName, payload.UseCookies, ctx)
+}
@@ -5,9 +5,10 @@ import (
 	"fmt"
 	"go-todo/gterrors"
 	"go-todo/logging"
-	"go-todo/schemas"
+	"go-todo/util/authcookie"
 	"go-todo/util/jwt"
 	"go-todo/util/mycontext"
+	"go-todo/util/revocation"
 	"net/http"
 	"runtime"
 
@@ -15,14 +16,11 @@ import (
 )
 
 func (controller *AuthController) Logout(ctx *gin.Context) {
-	// TODO Add access jwt to redis blacklist
-	var payload *schemas.Refresh
-	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+	refreshToken, fromCookie, ok := bindRefreshToken(ctx)
+	if !ok {
 		return
 	}
 
-	refreshToken := payload.RefreshToken
-
 	claims, err := jwt.DecodeRefreshToken(refreshToken)
 	if err != nil {
 		logTokenEventUse(false, claims, ctx)
@@ -70,6 +68,14 @@ func (controller *AuthController) Logout(ctx *gin.Context) {
 		return
 	}
 
+	// The access tokens issued for the session, also by earlier refreshes,
+	// would otherwise stay valid until they expire.
+	if err := revocation.RevokeFamily(ctx, claims.Family); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to revoke access tokens", file, line, err, ctx)
+		return
+	}
+
 	if rows, err := controller.db.DeleteJwtTokenByFamily(ctx, claims.Family); err != nil || rows == 0 {
 		_, file, line, _ := runtime.Caller(0)
 		errIfNil := fmt.Errorf("failed to delete jwt family: %w", err)
@@ -87,5 +93,8 @@ func (controller *AuthController) Logout(ctx *gin.Context) {
 		logging.SessionEventTypeLogout,
 		ctx.ClientIP(),
 	)
+	if fromCookie {
+		authcookie.Clear(ctx)
+	}
 	ctx.JSON(http.StatusNoContent, gin.H{})
 }
@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"errors"
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/config"
+	"go-todo/util/mycontext"
+	"go-todo/util/oidc"
+	"runtime"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Finishes a login started with StartOidcLogin. The identity is mapped to a
+// user, who gets a session like with a password login.
+func (controller *AuthController) OidcCallback(ctx *gin.Context) {
+	if controller.oidc == nil {
+		ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	var payload *schemas.OidcCallback
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	}
+	if ok := validDeviceName(payload.DeviceName, ctx); !ok {
+		return
+	}
+
+	oidcFailed := func(subject string, reason gterrors.GtAuthErrorReason, err error) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreMedium,
+			logging.SecurityEventOidcFailed,
+			ctx.FullPath(),
+			subject,
+			ctx.ClientIP(),
+		)
+		ctx.Error(gterrors.NewGtAuthError(reason, err)).SetType(gterrors.GetGinErrorType())
+	}
+
+	stateArgs := &db.UseOidcStateParams{
+		State:     payload.State,
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	}
+	state, err := controller.db.UseOidcState(ctx, *stateArgs)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			oidcFailed("", gterrors.GtAuthErrorReasonTokenInvalid, errors.New("login state is unknown or expired"))
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get oidc state from db", file, line, err, ctx)
+		return
+	}
+
+	claims, err := controller.oidc.Exchange(ctx, payload.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		if errors.Is(err, oidc.ErrIdTokenInvalid) {
+			oidcFailed("", gterrors.GtAuthErrorReasonInvalidCredentials, err)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to exchange oidc code", file, line, err, ctx)
+		return
+	}
+
+	user, ok := controller.getOidcUser(claims, ctx)
+	if !ok {
+		return
+	}
+	if user == nil {
+		oidcFailed(
+			claims.Subject,
+			gterrors.GtAuthErrorReasonInvalidCredentials,
+			errors.New("no user is linked to the identity"),
+		)
+		return
+	}
+
+	// The provider is a first factor like the password.
+	if ok := controller.passTotp(*user, ctx); !ok {
+		return
+	}
+	controller.startSession(*user, payload.DeviceName, payload.UseCookies, ctx)
+}
+
+// Returns the user linked to the identity. Links a user with the same
+// verified email or provisions a new user when the config allows it. Returns
+// a nil user if there is no user for the identity.
+func (controller *AuthController) getOidcUser(claims *oidc.Claims, ctx *gin.Context) (*db.User, bool) {
+	issuer := controller.oidc.Issuer()
+	identityArgs := &db.GetUserIdentityParams{
+		Issuer:  issuer,
+		Subject: claims.Subject,
+	}
+	identity, err := controller.db.GetUserIdentity(ctx, *identityArgs)
+	if err == nil {
+		// Should only fail if something is wrong in the server
+		user, err := controller.db.GetUserById(ctx, identity.UserID)
+		if err != nil {
+			_, file, line, _ := runtime.Caller(0)
+			mycontext.CtxAddGtInternalError("failed to get user from db", file, line, err, ctx)
+			return nil, false
+		}
+		return &user, true
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get identity from db", file, line, err, ctx)
+		return nil, false
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get config", file, line, err, ctx)
+		return nil, false
+	}
+
+	var user *db.User
+	if cfg.OidcLinkByEmail && claims.EmailVerified && claims.Email != "" {
+		userByEmail, err := controller.db.GetUserByEmail(ctx, claims.Email)
+		if err == nil {
+			// Only the owner of a confirmed address is linked, otherwise
+			// anyone could put the address on their account to take the
+			// identity.
+			if userByEmail.EmailVerifiedAt.Valid {
+				user = &userByEmail
+			}
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			_, file, line, _ := runtime.Caller(0)
+			mycontext.CtxAddGtInternalError("failed to get user from db", file, line, err, ctx)
+			return nil, false
+		}
+	}
+	if user == nil {
+		if !cfg.OidcAutoProvision {
+			return nil, true
+		}
+		var ok bool
+		// Only an address the provider has verified is taken.
+		email := ""
+		if claims.EmailVerified {
+			email = claims.Email
+		}
+		if user, ok = controller.provisionUser(usernameFromClaims(claims), email, ctx); !ok {
+			return nil, false
+		}
+	}
+
+	linkArgs := &db.CreateUserIdentityParams{
+		Issuer:  issuer,
+		Subject: claims.Subject,
+		UserID:  user.ID,
+	}
+	if err := controller.db.CreateUserIdentity(ctx, *linkArgs); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to link identity", file, line, err, ctx)
+		return nil, false
+	}
+	logging.LogSessionEvent(
+		true,
+		ctx.FullPath(),
+		user.Username,
+		logging.SessionEventTypeOidcLink,
+		ctx.ClientIP(),
+	)
+	return user, true
+}
+
+// Picks a username from the preferred username, the local part of the email
+// or the name, with the characters usernames can't have removed.
+func usernameFromClaims(claims *oidc.Claims) string {
+	localPart, _, _ := strings.Cut(claims.Email, "@")
+	for _, candidate := range []string{claims.PreferredUsername, localPart, claims.Name} {
+		username := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_' || r == '-' {
+				return r
+			}
+			return -1
+		}, candidate)
+		if len([]rune(username)) > 15 {
+			username = string([]rune(username)[:15])
+		}
+		if username != "" {
+			return username
+		}
+	}
+	return "user"
+}
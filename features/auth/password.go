@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"fmt"
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/util/config"
+	"go-todo/util/mycontext"
+	"go-todo/util/passwd"
+	"go-todo/util/revocation"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Returns the number of previous passwords kept in the history. The current
+// password counts as one of the last PASSWORD_HISTORY_SIZE passwords, so only
+// the rest are kept.
+func passwordHistorySize(ctx *gin.Context) (int, bool) {
+	cfg, err := config.Get()
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get config", file, line, err, ctx)
+		return 0, false
+	}
+	return max(cfg.PasswordHistorySize-1, 0), true
+}
+
+// Responds with ErrPasswordSame if the new password is the current one or in
+// the user's password history.
+func (controller *AuthController) checkPasswordReuse(user db.User, newPassword string, ctx *gin.Context) bool {
+	if passwd.Compare(newPassword, user.PasswordHash) {
+		ctx.Error(gterrors.ErrPasswordSame).SetType(gin.ErrorTypePublic)
+		return false
+	}
+
+	historySize, ok := passwordHistorySize(ctx)
+	if !ok || historySize == 0 {
+		return ok
+	}
+	historyArgs := &db.GetPasswordHistoryByUserIdParams{
+		UserID: user.ID,
+		Limit:  int32(historySize),
+	}
+	history, err := controller.db.GetPasswordHistoryByUserId(ctx, *historyArgs)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get password history", file, line, err, ctx)
+		return false
+	}
+	for _, oldHash := range history {
+		if passwd.Compare(newPassword, oldHash) {
+			ctx.Error(gterrors.ErrPasswordSame).SetType(gin.ErrorTypePublic)
+			return false
+		}
+	}
+	return true
+}
+
+// Saves the new password, revokes the user's access tokens and moves the old
+// password to the history. The new password must already be validated.
+func (controller *AuthController) setPassword(user db.User, newPassword string, ctx *gin.Context) bool {
+	newPasswordHash, err := passwd.Hash(newPassword)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to hash new password", file, line, err, ctx)
+		return false
+	}
+
+	args := &db.UpdateUserPasswordParams{
+		PasswordHash: newPasswordHash,
+		ID:           user.ID,
+	}
+
+	if err := controller.db.UpdateUserPassword(ctx, *args); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to update password to db", file, line, err, ctx)
+		return false
+	}
+
+	if err := revocation.RevokeUser(ctx, user.ID); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to revoke access tokens", file, line, err, ctx)
+		return false
+	}
+
+	historySize, ok := passwordHistorySize(ctx)
+	if !ok {
+		return false
+	}
+	if historySize > 0 {
+		historyArgs := &db.CreatePasswordHistoryParams{
+			ID:           uuid.New().String(),
+			UserID:       user.ID,
+			PasswordHash: user.PasswordHash,
+		}
+		if err := controller.db.CreatePasswordHistory(ctx, *historyArgs); err != nil {
+			_, file, line, _ := runtime.Caller(0)
+			mycontext.CtxAddGtInternalError("failed to save password history", file, line, err, ctx)
+			return false
+		}
+	}
+	pruneArgs := &db.DeletePasswordHistoryExceptNewestParams{
+		UserID: user.ID,
+		Keep:   int32(historySize),
+	}
+	if err := controller.db.DeletePasswordHistoryExceptNewest(ctx, *pruneArgs); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to prune password history", file, line, err, ctx)
+		return false
+	}
+	return true
+}
+
+// Hashes the password again if its hash uses an old algorithm or old
+// parameters. Failing is logged but doesn't fail the login, the next login
+// tries again.
+func (controller *AuthController) upgradePasswordHash(user db.User, password string, ctx *gin.Context) {
+	if !passwd.NeedsRehash(user.PasswordHash) {
+		return
+	}
+
+	newPasswordHash, err := passwd.Hash(password)
+	if err == nil {
+		args := &db.UpdateUserPasswordParams{
+			PasswordHash: newPasswordHash,
+			ID:           user.ID,
+		}
+		err = controller.db.UpdateUserPassword(ctx, *args)
+	}
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Failed to upgrade password hash.")
+	}
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/mycontext"
+	"go-todo/util/pat"
+	"go-todo/util/validate"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func toResponsePersonalToken(token db.PersonalAccessToken) schemas.ResponsePersonalToken {
+	response := schemas.ResponsePersonalToken{
+		ID:        token.ID,
+		Name:      token.Name,
+		Scopes:    token.Scopes,
+		CreatedAt: token.CreatedAt.Time,
+	}
+	if token.ExpiresAt.Valid {
+		response.ExpiresAt = &token.ExpiresAt.Time
+	}
+	if token.LastUsedAt.Valid {
+		response.LastUsedAt = &token.LastUsedAt.Time
+	}
+	return response
+}
+
+// Creates a personal access token. The token is only in this response, the
+// database has its hash.
+func (controller *AuthController) CreatePersonalToken(ctx *gin.Context) {
+	userID, username, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	var payload *schemas.CreatePersonalToken
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	}
+
+	if !validate.LengthTitle(payload.Name) {
+		ctx.Error(gterrors.NewGtValueError(payload.Name, "name too long")).SetType(gin.ErrorTypePublic)
+		return
+	}
+	if err := pat.ValidateScopes(payload.Scopes); err != nil {
+		ctx.Error(gterrors.NewGtValueError(strings.Join(payload.Scopes, " "), err.Error())).SetType(gin.ErrorTypePublic)
+		return
+	}
+	expiresAt := pgtype.Timestamp{}
+	if payload.ExpiresAt != nil {
+		if !payload.ExpiresAt.After(time.Now()) {
+			ctx.Error(gterrors.NewGtValueError(payload.ExpiresAt.String(), "expiry must be in the future")).SetType(gin.ErrorTypePublic)
+			return
+		}
+		expiresAt = pgtype.Timestamp{Time: payload.ExpiresAt.UTC(), Valid: true}
+	}
+
+	token, hash, err := pat.Generate()
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to generate personal access token", file, line, err, ctx)
+		return
+	}
+
+	created, err := controller.db.CreatePersonalAccessToken(ctx, db.CreatePersonalAccessTokenParams{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      payload.Name,
+		TokenHash: hash,
+		Scopes:    payload.Scopes,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to save personal access token", file, line, err, ctx)
+		return
+	}
+
+	logging.LogSessionEvent(
+		true,
+		ctx.FullPath(),
+		username,
+		logging.SessionEventTypePersonalTokenCreate,
+		ctx.ClientIP(),
+	)
+	ctx.JSON(http.StatusCreated, gin.H{
+		"status":         "ok",
+		"token":          token,
+		"personal_token": toResponsePersonalToken(created),
+	})
+}
+
+// Lists the personal access tokens of the user without the tokens themselves.
+func (controller *AuthController) ReadPersonalTokens(ctx *gin.Context) {
+	userID, _, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	tokens, err := controller.db.GetPersonalAccessTokensByUserId(ctx, userID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get personal access tokens", file, line, err, ctx)
+		return
+	}
+
+	response := make([]schemas.ResponsePersonalToken, 0, len(tokens))
+	for _, token := range tokens {
+		response = append(response, toResponsePersonalToken(token))
+	}
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok", "personal_tokens": response})
+}
+
+// Revokes a personal access token. It stops working right away.
+func (controller *AuthController) DeletePersonalToken(ctx *gin.Context) {
+	userID, username, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	rows, err := controller.db.DeletePersonalAccessToken(ctx, db.DeletePersonalAccessTokenParams{
+		ID:     ctx.Param("tokenID"),
+		UserID: userID,
+	})
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to delete personal access token", file, line, err, ctx)
+		return
+	} else if rows == 0 {
+		ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	logging.LogSessionEvent(
+		true,
+		ctx.FullPath(),
+		username,
+		logging.SessionEventTypePersonalTokenRevoke,
+		ctx.ClientIP(),
+	)
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	db "go-todo/db/sqlc"
+	"go-todo/logging"
+	"go-todo/util/authn"
+	"go-todo/util/config"
+	"go-todo/util/mycontext"
+	"go-todo/util/oidc"
+	"go-todo/util/passwd"
+	"go-todo/util/validate"
+	"math/big"
+	"runtime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Returns the user of an identity from authn.Authenticate. Identities from
+// external authenticators are linked to a user on their first login, which
+// provisions a new user when the config allows it. Returns a nil user if
+// there is no user for the identity.
+func (controller *AuthController) getIdentityUser(identity *authn.Identity, ctx *gin.Context) (*db.User, bool) {
+	if identity.User != nil {
+		return identity.User, true
+	}
+
+	identityArgs := &db.GetUserIdentityParams{
+		Issuer:  identity.Issuer,
+		Subject: identity.Subject,
+	}
+	linked, err := controller.db.GetUserIdentity(ctx, *identityArgs)
+	if err == nil {
+		// Should only fail if something is wrong in the server
+		user, err := controller.db.GetUserById(ctx, linked.UserID)
+		if err != nil {
+			_, file, line, _ := runtime.Caller(0)
+			mycontext.CtxAddGtInternalError("failed to get user from db", file, line, err, ctx)
+			return nil, false
+		}
+		return &user, true
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get identity from db", file, line, err, ctx)
+		return nil, false
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get config", file, line, err, ctx)
+		return nil, false
+	}
+	if identity.Provider != authn.ProviderLdap || !cfg.LdapAutoProvision {
+		return nil, true
+	}
+	// The directory is trusted with the address.
+	user, ok := controller.provisionUser(identity.Username, identity.Email, ctx)
+	if !ok {
+		return nil, false
+	}
+
+	linkArgs := &db.CreateUserIdentityParams{
+		Issuer:  identity.Issuer,
+		Subject: identity.Subject,
+		UserID:  user.ID,
+	}
+	if err := controller.db.CreateUserIdentity(ctx, *linkArgs); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to link identity", file, line, err, ctx)
+		return nil, false
+	}
+	logging.LogSessionEvent(
+		true,
+		ctx.FullPath(),
+		user.Username,
+		logging.SessionEventTypeLdapLink,
+		ctx.ClientIP(),
+	)
+	return user, true
+}
+
+const provisionAttempts = 5
+
+// Creates a user for an identity from an external provider. The username is
+// base with a random suffix if base is taken. The email must be verified by
+// the provider, it's left out if another user has it. The user gets a random
+// password, so they can only log in through the provider until they reset it.
+func (controller *AuthController) provisionUser(base string, email string, ctx *gin.Context) (*db.User, bool) {
+	password, err := oidc.RandomString()
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to generate password", file, line, err, ctx)
+		return nil, false
+	}
+	passwordHash, err := passwd.Hash(password)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to hash password", file, line, err, ctx)
+		return nil, false
+	}
+
+	for attempt := range provisionAttempts {
+		username := base
+		if attempt > 0 {
+			suffix, err := rand.Int(rand.Reader, big.NewInt(10000))
+			if err != nil {
+				_, file, line, _ := runtime.Caller(0)
+				mycontext.CtxAddGtInternalError("failed to generate username", file, line, err, ctx)
+				return nil, false
+			}
+			username = fmt.Sprintf("%v-%04d", base, suffix.Int64())
+		}
+		if ok, err := validate.Username(username); err != nil || !ok {
+			continue
+		}
+
+		args := &db.CreateUserParams{
+			ID:           uuid.New().String(),
+			Username:     username,
+			PasswordHash: passwordHash,
+			IsAdmin:      false,
+		}
+		created, err := controller.db.CreateUser(ctx, *args)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				continue
+			}
+			_, file, line, _ := runtime.Caller(0)
+			mycontext.CtxAddGtInternalError("failed to create user", file, line, err, ctx)
+			return nil, false
+		}
+		logging.LogObjectEvent(
+			ctx.FullPath(),
+			ctx.ClientIP(),
+			logging.ObjectEventCreate,
+			nil,
+			mycontext.GetTokenAct(ctx),
+			&created,
+			nil,
+			logging.ObjectEventSubUser,
+		)
+
+		if email != "" && validate.Email(email) {
+			emailArgs := &db.VerifyUserEmailParams{
+				ID:              created.ID,
+				Email:           pgtype.Text{String: email, Valid: true},
+				EmailVerifiedAt: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+			}
+			// The address may already belong to another user, in which case
+			// the new user is left without one.
+			if err := controller.db.VerifyUserEmail(ctx, *emailArgs); err != nil {
+				var pgErr *pgconn.PgError
+				if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
+					_, file, line, _ := runtime.Caller(0)
+					mycontext.CtxAddGtInternalError("failed to set email", file, line, err, ctx)
+					return nil, false
+				}
+			}
+		}
+
+		// Should only fail if something is wrong in the server
+		user, err := controller.db.GetUserById(ctx, created.ID)
+		if err != nil {
+			_, file, line, _ := runtime.Caller(0)
+			mycontext.CtxAddGtInternalError("failed to get user from db", file, line, err, ctx)
+			return nil, false
+		}
+		return &user, true
+	}
+
+	_, file, line, _ := runtime.Caller(0)
+	mycontext.CtxAddGtInternalError(
+		"failed to create user",
+		file,
+		line,
+		fmt.Errorf("no free username for %v", base),
+		ctx,
+	)
+	return nil, false
+}
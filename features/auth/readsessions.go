@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"go-todo/schemas"
+	"go-todo/util/mycontext"
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lists the active sessions (refresh token families) of the user.
+func (controller *AuthController) ReadSessions(ctx *gin.Context) {
+	reqUser, target, ok := controller.getSessionUsers(ctx)
+	if !ok {
+		return
+	}
+
+	sessions, err := controller.db.GetSessionsByUserId(ctx, target.ID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get sessions", file, line, err, ctx)
+		return
+	}
+
+	currentFamily := ""
+	if reqUser.ID == target.ID {
+		currentFamily = mycontext.GetTokenFamily(ctx)
+	}
+	response := make([]schemas.ResponseSession, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, schemas.ResponseSession{
+			Family:           session.Family,
+			DeviceName:       session.DeviceName,
+			CreatedAt:        session.CreatedAt.Time,
+			LastUsedAt:       session.LastUsedAt.Time,
+			ExpiresAt:        session.ExpiresAt.Time,
+			CreatedIp:        session.CreatedIp,
+			CreatedUserAgent: session.CreatedUserAgent,
+			Ip:               session.Ip,
+			UserAgent:        session.UserAgent,
+			Current:          session.Family == currentFamily,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok", "sessions": response})
+}
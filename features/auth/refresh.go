@@ -6,10 +6,9 @@ import (
 	db "go-todo/db/sqlc"
 	"go-todo/gterrors"
 	"go-todo/logging"
-	"go-todo/schemas"
 	"go-todo/util/jwt"
 	"go-todo/util/mycontext"
-	"net/http"
+	"go-todo/util/revocation"
 	"runtime"
 
 	"github.com/gin-gonic/gin"
@@ -18,13 +17,11 @@ import (
 )
 
 func (controller *AuthController) Refresh(ctx *gin.Context) {
-	var payload *schemas.Refresh
-	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+	refreshToken, fromCookie, ok := bindRefreshToken(ctx)
+	if !ok {
 		return
 	}
 
-	refreshToken := payload.RefreshToken
-
 	decodedRefreshToken, err := jwt.DecodeRefreshToken(refreshToken)
 	if err != nil {
 		logTokenEventUse(false, decodedRefreshToken, ctx)
@@ -51,6 +48,21 @@ func (controller *AuthController) Refresh(ctx *gin.Context) {
 	}
 
 	dbToken, err := controller.db.GetJwtTokenByJti(ctx, decodedRefreshToken.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The collector deletes used tokens after TOKEN_GC_USED_GRACE, while
+		// the session stays as long as the family has tokens left. An
+		// unknown token of a known session was used and collected.
+		_, sessionErr := controller.db.GetSession(ctx, decodedRefreshToken.Family)
+		if sessionErr == nil {
+			controller.refreshTokenReused(decodedRefreshToken, ctx)
+			return
+		} else if !errors.Is(sessionErr, pgx.ErrNoRows) {
+			logTokenEventUse(false, decodedRefreshToken, ctx)
+			_, file, line, _ := runtime.Caller(0)
+			mycontext.CtxAddGtInternalError("failed to get session", file, line, sessionErr, ctx)
+			return
+		}
+	}
 	if err != nil {
 		logTokenEventUse(false, decodedRefreshToken, ctx)
 		logging.LogSecurityEvent(
@@ -76,30 +88,7 @@ func (controller *AuthController) Refresh(ctx *gin.Context) {
 	}
 
 	if dbToken.IsUsed {
-		logTokenEventUse(false, decodedRefreshToken, ctx)
-		logging.LogSecurityEvent(
-			logging.SecurityScoreCritical,
-			logging.SecurityEventJwtReuse,
-			ctx.FullPath(),
-			decodedRefreshToken.ID,
-			ctx.ClientIP(),
-		)
-		ginType := gterrors.GetGinErrorType()
-		if rows, err := controller.db.DeleteJwtTokenByFamily(ctx, dbToken.Family); err != nil || rows == 0 {
-			_, file, line, _ := runtime.Caller(0)
-			errIfNil := fmt.Errorf("failed to delete jwt family: %w", err)
-			if err == nil {
-				errIfNil = fmt.Errorf("failed to delete jwt family: %v", dbToken.Family)
-			}
-			mycontext.CtxAddGtInternalError("", file, line, errIfNil, ctx)
-			return
-		}
-		ctx.Error(
-			gterrors.NewGtAuthError(
-				gterrors.GtAuthErrorReasonTokenReuse,
-				gterrors.ErrJwtRefreshReuse,
-			),
-		).SetType(ginType)
+		controller.refreshTokenReused(decodedRefreshToken, ctx)
 		return
 	}
 
@@ -123,6 +112,12 @@ func (controller *AuthController) Refresh(ctx *gin.Context) {
 		)
 	}
 
+	if ok := controller.useSession(decodedRefreshToken.Family, user, ctx); !ok {
+		logSessionRefresh(false)
+		logTokenEventUse(false, decodedRefreshToken, ctx)
+		return
+	}
+
 	refreshToken, refreshClaims, accessToken, accessClaims, err := generateTokens(
 		decodedRefreshToken.Family,
 		user,
@@ -150,6 +145,8 @@ func (controller *AuthController) Refresh(ctx *gin.Context) {
 		Family:    refreshClaims.Family,
 		CreatedAt: pgtype.Timestamp{Time: refreshClaims.IssuedAt.Time, Valid: true},
 		ExpiresAt: pgtype.Timestamp{Time: refreshClaims.ExpiresAt.Time, Valid: true},
+		Ip:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
 	}
 
 	if err := controller.db.CreateJwtToken(ctx, *args); err != nil {
@@ -163,9 +160,37 @@ func (controller *AuthController) Refresh(ctx *gin.Context) {
 	logSessionRefresh(true)
 	logTokenCreations([]*jwt.GtClaims{refreshClaims, accessClaims}, ctx)
 	logTokenEventUse(true, decodedRefreshToken, ctx)
-	ctx.JSON(http.StatusOK, gin.H{
-		"status":        "ok",
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
-	})
+	respondWithTokens(refreshToken, refreshClaims, accessToken, accessClaims, fromCookie, ctx)
+}
+
+// Ends the session of a refresh token that was used before, as either the
+// client or whoever has a copy of the token is using an old token.
+func (controller *AuthController) refreshTokenReused(claims *jwt.GtClaims, ctx *gin.Context) {
+	logTokenEventUse(false, claims, ctx)
+	logging.LogSecurityEvent(
+		logging.SecurityScoreCritical,
+		logging.SecurityEventJwtReuse,
+		ctx.FullPath(),
+		claims.ID,
+		ctx.ClientIP(),
+	)
+	// The access tokens issued for the family may be in the hands of
+	// whoever reused the token.
+	if err := revocation.RevokeFamily(ctx, claims.Family); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to revoke jwt family", file, line, err, ctx)
+		return
+	}
+	// The family may have no tokens left if the session already ended.
+	if _, err := controller.db.DeleteJwtTokenByFamily(ctx, claims.Family); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to delete jwt family", file, line, err, ctx)
+		return
+	}
+	ctx.Error(
+		gterrors.NewGtAuthError(
+			gterrors.GtAuthErrorReasonTokenReuse,
+			gterrors.ErrJwtRefreshReuse,
+		),
+	).SetType(gterrors.GetGinErrorType())
 }
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/config"
+	"go-todo/util/mailer"
+	"go-todo/util/mycontext"
+	"go-todo/util/securetoken"
+	"go-todo/util/validate"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Mails a password reset token to the owner of the address. The response is
+// the same whether or not the address belongs to an account.
+func (controller *AuthController) RequestPasswordReset(ctx *gin.Context) {
+	if controller.mailer == nil {
+		ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	var payload *schemas.RequestPasswordReset
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	}
+
+	if !validate.Email(payload.Email) {
+		ctx.Error(gterrors.NewGtValueError(payload.Email, "invalid email address")).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	respond := func() {
+		ctx.JSON(http.StatusAccepted, gin.H{"status": "ok"})
+	}
+
+	user, err := controller.db.GetUserByEmail(ctx, payload.Email)
+	// Resets only go to confirmed addresses.
+	if err == nil && !user.EmailVerifiedAt.Valid {
+		err = pgx.ErrNoRows
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logging.LogSecurityEvent(
+				logging.SecurityScoreLow,
+				logging.SecurityEventPasswordResetUnknownEmail,
+				ctx.FullPath(),
+				payload.Email,
+				ctx.ClientIP(),
+			)
+			respond()
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get user from db", file, line, err, ctx)
+		return
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get config", file, line, err, ctx)
+		return
+	}
+
+	// The token is made and mailed in the background, so the response takes
+	// as long as for an unknown address.
+	go controller.sendPasswordResetToken(cfg, user)
+
+	respond()
+}
+
+// Replaces the user's reset tokens with a new one and mails it. Errors are
+// logged, as the request has been answered already.
+func (controller *AuthController) sendPasswordResetToken(cfg *config.Config, user db.User) {
+	ctx := context.Background()
+	token, tokenHash, err := securetoken.Generate()
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Failed to generate password reset token.")
+		return
+	}
+
+	// Only the newest token works.
+	if err := controller.db.DeletePasswordResetTokensByUserId(ctx, user.ID); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Failed to delete old password reset tokens.")
+		return
+	}
+	lifeSpan := time.Duration(cfg.PasswordResetLifeSpan) * time.Minute
+	args := &db.CreatePasswordResetTokenParams{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().Add(lifeSpan).UTC(), Valid: true},
+	}
+	if err := controller.db.CreatePasswordResetToken(ctx, *args); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Failed to save password reset token.")
+		return
+	}
+
+	if err := controller.mailer.Send(ctx, passwordResetMessage(cfg, user, token)); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Failed to send password reset mail.")
+	}
+}
+
+func passwordResetMessage(cfg *config.Config, user db.User, token string) mailer.Message {
+	instructions := fmt.Sprintf("Use this token to choose a new password: %v", token)
+	if cfg.PasswordResetUrl != "" {
+		separator := "?"
+		if strings.Contains(cfg.PasswordResetUrl, "?") {
+			separator = "&"
+		}
+		instructions = fmt.Sprintf(
+			"Use this link to choose a new password: %v%vtoken=%v",
+			cfg.PasswordResetUrl,
+			separator,
+			token,
+		)
+	}
+	return mailer.Message{
+		To:      user.Email.String,
+		Subject: "Password reset",
+		Body: fmt.Sprintf(
+			"A password reset was requested for the account %v.\n\n%v\n\n"+
+				"It expires in %d minutes and works once. If you didn't request "+
+				"the reset, you can ignore this mail.",
+			user.Username,
+			instructions,
+			cfg.PasswordResetLifeSpan,
+		),
+	}
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	db "go-todo/db/sqlc"
+	"go-todo/logging"
+	"go-todo/util/mycontext"
+	"go-todo/util/revocation"
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Ends every session of the user except the one the request was made with.
+// When an admin targets another user, all of that user's sessions are ended.
+func (controller *AuthController) RevokeOtherSessions(ctx *gin.Context) {
+	reqUser, target, ok := controller.getSessionUsers(ctx)
+	if !ok {
+		return
+	}
+
+	currentFamily := ""
+	if reqUser.ID == target.ID {
+		currentFamily = mycontext.GetTokenFamily(ctx)
+	}
+
+	sessions, err := controller.db.GetSessionsByUserId(ctx, target.ID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get sessions", file, line, err, ctx)
+		return
+	}
+	families := []string{}
+	for _, session := range sessions {
+		if session.Family != currentFamily {
+			families = append(families, session.Family)
+		}
+	}
+
+	if currentFamily != "" {
+		deleteArgs := &db.DeleteJwtTokenByUserIdExcludeFamilyParams{
+			UserID: target.ID,
+			Family: currentFamily,
+		}
+		err = controller.db.DeleteJwtTokenByUserIdExcludeFamily(ctx, *deleteArgs)
+	} else {
+		err = controller.db.DeleteJwtTokensByUserId(ctx, target.ID)
+	}
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to delete jwt families", file, line, err, ctx)
+		return
+	}
+
+	for _, family := range families {
+		if err := revocation.RevokeFamily(ctx, family); err != nil {
+			_, file, line, _ := runtime.Caller(0)
+			mycontext.CtxAddGtInternalError("failed to revoke access tokens", file, line, err, ctx)
+			return
+		}
+	}
+
+	logging.LogSessionRevokeEvent(
+		ctx.FullPath(),
+		reqUser.Username,
+		target.Username,
+		families,
+		ctx.ClientIP(),
+	)
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok", "revoked": len(families)})
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/util/mycontext"
+	"go-todo/util/revocation"
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Ends a single session of the user. The refresh tokens of the session are
+// deleted and its access tokens revoked.
+func (controller *AuthController) RevokeSession(ctx *gin.Context) {
+	reqUser, target, ok := controller.getSessionUsers(ctx)
+	if !ok {
+		return
+	}
+
+	family := ctx.Param("family")
+	args := &db.DeleteJwtTokenByUserIdAndFamilyParams{
+		UserID: target.ID,
+		Family: family,
+	}
+	rows, err := controller.db.DeleteJwtTokenByUserIdAndFamily(ctx, *args)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to delete jwt family", file, line, err, ctx)
+		return
+	} else if rows == 0 {
+		ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	if err := revocation.RevokeFamily(ctx, family); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to revoke access tokens", file, line, err, ctx)
+		return
+	}
+
+	logging.LogSessionRevokeEvent(
+		ctx.FullPath(),
+		reqUser.Username,
+		target.Username,
+		[]string{family},
+		ctx.ClientIP(),
+	)
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
@@ -17,7 +17,43 @@ func NewRoutes(authController *AuthController) *AuthRoutes {
 func (routes *AuthRoutes) Register(rg *gin.RouterGroup) {
 	router := rg.Group("/auth")
 	router.POST("/login", routes.authController.Login)
+	router.POST("/login/totp", routes.authController.LoginTotp)
 	router.POST("/logout", middleware.JwtAuthMiddleware(), routes.authController.Logout)
 	router.POST("/refresh", routes.authController.Refresh)
-	router.POST("/update-password", middleware.JwtAuthMiddleware(), routes.authController.UpdatePassword)
+	router.POST("/update-password", middleware.JwtAuthMiddleware(), middleware.DenyImpersonationMiddleware(), routes.authController.UpdatePassword)
+	router.POST("/password-reset", routes.authController.RequestPasswordReset)
+	router.POST("/password-reset/confirm", routes.authController.ConfirmPasswordReset)
+	router.GET("/oidc/login", routes.authController.StartOidcLogin)
+	router.POST("/oidc/callback", routes.authController.OidcCallback)
+
+	sessionRouter := router.Group("/sessions")
+	sessionRouter.Use(middleware.JwtAuthMiddleware(), middleware.DenyImpersonationMiddleware())
+	sessionRouter.GET("/", routes.authController.ReadSessions)
+	sessionRouter.DELETE("/", routes.authController.RevokeOtherSessions)
+	sessionRouter.DELETE("/:family", routes.authController.RevokeSession)
+
+	// Personal access tokens can't manage personal access tokens.
+	tokenRouter := router.Group("/tokens")
+	tokenRouter.Use(middleware.JwtAuthMiddleware(), middleware.DenyImpersonationMiddleware())
+	tokenRouter.GET("/", routes.authController.ReadPersonalTokens)
+	tokenRouter.POST("/", routes.authController.CreatePersonalToken)
+	tokenRouter.DELETE("/:tokenID", routes.authController.DeletePersonalToken)
+
+	totpRouter := router.Group("/totp")
+	totpRouter.Use(middleware.JwtAuthMiddleware(), middleware.DenyImpersonationMiddleware())
+	totpRouter.POST("/enroll", routes.authController.EnrollTotp)
+	totpRouter.POST("/confirm", routes.authController.ConfirmTotp)
+	totpRouter.POST("/disable", routes.authController.DisableTotp)
+}
+
+// Registered apart from the other auth routes so services calling it get the
+// rate limit of rg instead of the login limit.
+func (routes *AuthRoutes) RegisterIntrospection(rg *gin.RouterGroup) {
+	rg.POST("/auth/introspect", routes.authController.Introspect)
+}
+
+// Routes served outside the api prefix so other services find them at the
+// standard location.
+func (routes *AuthRoutes) RegisterWellKnown(rg *gin.RouterGroup) {
+	rg.GET("/jwks.json", routes.authController.Jwks)
 }
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"errors"
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/util/config"
+	"go-todo/util/fingerprint"
+	"go-todo/util/mycontext"
+	"go-todo/util/revocation"
+	"go-todo/util/validate"
+	"runtime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Responds with a value error if the client supplied device name is too
+// long.
+func validDeviceName(deviceName string, ctx *gin.Context) bool {
+	if !validate.LengthTitle(deviceName) {
+		ctx.Error(gterrors.NewGtValueError(deviceName, "device name too long")).SetType(gin.ErrorTypePublic)
+		return false
+	}
+	return true
+}
+
+// Records where the session with the refresh token family was created and
+// ends the oldest sessions of the user if there are more than
+// SESSION_MAX_PER_USER. Must be called after the first refresh token of the
+// family is saved.
+func (controller *AuthController) saveSession(family string, user db.User, deviceName string, ctx *gin.Context) bool {
+	args := &db.CreateSessionParams{
+		Family:     family,
+		UserID:     user.ID,
+		DeviceName: deviceName,
+		Ip:         ctx.ClientIP(),
+		UserAgent:  ctx.Request.UserAgent(),
+		CreatedAt:  pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	}
+	if err := controller.db.CreateSession(ctx, *args); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to save session", file, line, err, ctx)
+		return false
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get config", file, line, err, ctx)
+		return false
+	}
+	if cfg.SessionMaxPerUser <= 0 {
+		return true
+	}
+
+	evictArgs := &db.GetSessionFamiliesToEvictParams{
+		UserID: user.ID,
+		Keep:   int32(cfg.SessionMaxPerUser),
+	}
+	families, err := controller.db.GetSessionFamiliesToEvict(ctx, *evictArgs)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get sessions to evict", file, line, err, ctx)
+		return false
+	}
+	for _, evicted := range families {
+		if _, err := controller.db.DeleteJwtTokenByFamily(ctx, evicted); err != nil {
+			_, file, line, _ := runtime.Caller(0)
+			mycontext.CtxAddGtInternalError("failed to delete jwt family", file, line, err, ctx)
+			return false
+		}
+		if err := revocation.RevokeFamily(ctx, evicted); err != nil {
+			_, file, line, _ := runtime.Caller(0)
+			mycontext.CtxAddGtInternalError("failed to revoke access tokens", file, line, err, ctx)
+			return false
+		}
+	}
+	if len(families) > 0 {
+		logging.LogSessionRevokeEvent(
+			ctx.FullPath(),
+			user.Username,
+			user.Username,
+			families,
+			ctx.ClientIP(),
+		)
+	}
+	return true
+}
+
+// Rejects a refresh when the client doesn't look like the one that created
+// the session, as SESSION_FINGERPRINT_POLICY requires, and otherwise records
+// the use of the session.
+func (controller *AuthController) useSession(family string, user db.User, ctx *gin.Context) bool {
+	cfg, err := config.Get()
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get config", file, line, err, ctx)
+		return false
+	}
+	policy, err := fingerprint.ParsePolicy(cfg.SessionFingerprintPolicy)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to parse fingerprint policy", file, line, err, ctx)
+		return false
+	}
+
+	session, err := controller.db.GetSession(ctx, family)
+	if err != nil {
+		// Sessions are saved after their first token, so a refresh can
+		// only miss one in a race with the collector.
+		if errors.Is(err, pgx.ErrNoRows) {
+			return true
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get session", file, line, err, ctx)
+		return false
+	}
+
+	created := fingerprint.New(session.UserAgent, session.Ip)
+	current := fingerprint.New(ctx.Request.UserAgent(), ctx.ClientIP())
+	if !created.Matches(current, policy) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreHigh,
+			logging.SecurityEventSessionFingerprintMismatch,
+			ctx.FullPath(),
+			user.Username,
+			ctx.ClientIP(),
+		)
+		ctx.Error(
+			gterrors.NewGtAuthError(
+				gterrors.GtAuthErrorReasonTokenInvalid,
+				errors.New("refresh from a different client than the session was created with"),
+			),
+		).SetType(gterrors.GetGinErrorType())
+		return false
+	}
+
+	touchArgs := &db.TouchSessionParams{
+		Family:     family,
+		LastUsedAt: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	}
+	if err := controller.db.TouchSession(ctx, *touchArgs); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to update session", file, line, err, ctx)
+		return false
+	}
+	return true
+}
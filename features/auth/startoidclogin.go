@@ -0,0 +1,76 @@
+package auth
+
+import (
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/util/config"
+	"go-todo/util/mycontext"
+	"go-todo/util/oidc"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Starts a login at the identity provider. Responds with the URL the user is
+// sent to. The provider redirects back to OIDC_REDIRECT_URL with a code and
+// the state, which are passed to OidcCallback.
+func (controller *AuthController) StartOidcLogin(ctx *gin.Context) {
+	if controller.oidc == nil {
+		ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get config", file, line, err, ctx)
+		return
+	}
+
+	var values [3]string
+	for i := range values {
+		values[i], err = oidc.RandomString()
+		if err != nil {
+			_, file, line, _ := runtime.Caller(0)
+			mycontext.CtxAddGtInternalError("failed to generate oidc state", file, line, err, ctx)
+			return
+		}
+	}
+	state, nonce, codeVerifier := values[0], values[1], values[2]
+
+	authorizationURL, err := controller.oidc.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to reach identity provider", file, line, err, ctx)
+		return
+	}
+
+	now := time.Now().UTC()
+	if err := controller.db.DeleteExpiredOidcStates(ctx, pgtype.Timestamp{Time: now, Valid: true}); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to delete expired oidc states", file, line, err, ctx)
+		return
+	}
+	args := &db.CreateOidcStateParams{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt: pgtype.Timestamp{
+			Time:  now.Add(time.Duration(cfg.OidcStateLifeSpan) * time.Minute),
+			Valid: true,
+		},
+	}
+	if err := controller.db.CreateOidcState(ctx, *args); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to save oidc state", file, line, err, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status":            "ok",
+		"authorization_url": authorizationURL,
+	})
+}
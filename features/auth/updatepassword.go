@@ -5,13 +5,14 @@ import (
 	db "go-todo/db/sqlc"
 	"go-todo/gterrors"
 	"go-todo/schemas"
+	"go-todo/util/authcookie"
 	"go-todo/util/mycontext"
 	"go-todo/util/passwd"
 	"go-todo/util/validate"
-	"net/http"
 	"runtime"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -28,16 +29,6 @@ func (controller *AuthController) UpdatePassword(ctx *gin.Context) {
 		return
 	}
 
-	isPasswdValid, err := validate.Password(payload.NewPassword)
-	if err != nil {
-		_, file, line, _ := runtime.Caller(0)
-		mycontext.CtxAddGtInternalError("error validating password", file, line, err, ctx)
-		return
-	} else if !isPasswdValid {
-		ctx.Error(gterrors.ErrPasswordUnsatisfied).SetType(gin.ErrorTypePublic)
-		return
-	}
-
 	// Should only fail if something is wrong in the server
 	user, err := controller.db.GetUserById(ctx, userID)
 	if err != nil {
@@ -46,6 +37,11 @@ func (controller *AuthController) UpdatePassword(ctx *gin.Context) {
 		return
 	}
 
+	if err := validate.Password(payload.NewPassword, user.Username); err != nil {
+		ctx.Error(err).SetType(gin.ErrorTypePublic)
+		return
+	}
+
 	if !passwd.Compare(payload.OldPassword, user.PasswordHash) {
 		ctx.Error(
 			gterrors.NewGtAuthError(
@@ -54,30 +50,16 @@ func (controller *AuthController) UpdatePassword(ctx *gin.Context) {
 			),
 		).SetType(gin.ErrorTypePublic)
 		return
-	} else if passwd.Compare(payload.NewPassword, user.PasswordHash) {
-		ctx.Error(gterrors.ErrPasswordSame).SetType(gin.ErrorTypePublic)
-		return
 	}
 
-	newPasswordHash, err := passwd.Hash(payload.NewPassword)
-	if err != nil {
-		_, file, line, _ := runtime.Caller(0)
-		mycontext.CtxAddGtInternalError("failed to hash new password", file, line, err, ctx)
+	if ok := controller.checkPasswordReuse(user, payload.NewPassword, ctx); !ok {
 		return
 	}
-
-	args := &db.UpdateUserPasswordParams{
-		PasswordHash: newPasswordHash,
-		ID:           user.ID,
-	}
-
-	if err := controller.db.UpdateUserPassword(ctx, *args); err != nil {
-		_, file, line, _ := runtime.Caller(0)
-		mycontext.CtxAddGtInternalError("failed to update password to db", file, line, err, ctx)
+	if ok := controller.setPassword(user, payload.NewPassword, ctx); !ok {
 		return
 	}
 
-	refreshToken, refreshClaims, accessToken, _, err := generateTokens(
+	refreshToken, refreshClaims, accessToken, accessClaims, err := generateTokens(
 		"",
 		user,
 	)
@@ -93,6 +75,8 @@ func (controller *AuthController) UpdatePassword(ctx *gin.Context) {
 		Family:    refreshClaims.Family,
 		CreatedAt: pgtype.Timestamp{Time: refreshClaims.IssuedAt.Time, Valid: true},
 		ExpiresAt: pgtype.Timestamp{Time: refreshClaims.ExpiresAt.Time, Valid: true},
+		Ip:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
 	}
 
 	if err := controller.db.CreateJwtToken(ctx, *refreshArgs); err != nil {
@@ -101,6 +85,20 @@ func (controller *AuthController) UpdatePassword(ctx *gin.Context) {
 		return
 	}
 
+	// The new session replaces the current one, so it keeps its device name.
+	deviceName := ""
+	currentSession, err := controller.db.GetSession(ctx, mycontext.GetTokenFamily(ctx))
+	if err == nil {
+		deviceName = currentSession.DeviceName
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get session", file, line, err, ctx)
+		return
+	}
+	if ok := controller.saveSession(refreshClaims.Family, user, deviceName, ctx); !ok {
+		return
+	}
+
 	deleteArgs := &db.DeleteJwtTokenByUserIdExcludeFamilyParams{
 		UserID: userID,
 		Family: refreshClaims.Family,
@@ -111,9 +109,7 @@ func (controller *AuthController) UpdatePassword(ctx *gin.Context) {
 		mycontext.CtxAddGtInternalError("failed to remove old refresh jwts", file, line, err, ctx)
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"status":        "ok",
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
-	})
+	// Cookie clients get the new session as cookies too.
+	useCookies := authcookie.RefreshToken(ctx) != ""
+	respondWithTokens(refreshToken, refreshClaims, accessToken, accessClaims, useCookies, ctx)
 }
@@ -0,0 +1,206 @@
+package listtemplate
+
+import (
+	"errors"
+	"runtime"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+	"go-todo/util/validate"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Deep clones a list with its todos. The clone is owned by the requester and
+// its todos start as not completed.
+func (controller *TemplateController) CloneList(ctx *gin.Context) {
+	var payload *schemas.CloneList
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	}
+
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	listID := ctx.Param("listID")
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	if ok := controller.canAccessList(reqUser, listID, ctx); !ok {
+		return
+	}
+
+	oldList, err := controller.db.GetList(ctx, listID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get list", file, line, err, ctx)
+		return
+	}
+
+	title := oldList.Title
+	if payload.Title != nil {
+		title = *payload.Title
+	}
+	if !validate.LengthTitle(title) {
+		ctx.Error(gterrors.NewGtValueError(title, "title too long"))
+		return
+	}
+
+	oldTodos, err := controller.db.GetTodosByList(ctx, oldList.ID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get todos", file, line, err, ctx)
+		return
+	}
+	oldTodos = orderParentsFirst(
+		oldTodos,
+		func(t db.Todo) string { return t.ID },
+		func(t db.Todo) pgtype.Text { return t.ParentID },
+	)
+
+	oldStates, err := controller.db.GetListStatesByListId(ctx, oldList.ID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get states", file, line, err, ctx)
+		return
+	}
+
+	oldFields, err := controller.db.GetListFieldsByListId(ctx, oldList.ID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get fields", file, line, err, ctx)
+		return
+	}
+	oldValues, err := controller.db.GetTodoFieldValuesByListId(ctx, oldList.ID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get field values", file, line, err, ctx)
+		return
+	}
+
+	var list db.List
+	todos := make([]db.Todo, 0, len(oldTodos))
+	err = database.WithTx(ctx, controller.pool, controller.db, func(q *db.Queries) error {
+		var err error
+		list, err = q.CreateList(ctx, db.CreateListParams{
+			ID:          uuid.New().String(),
+			UserID:      reqUser.ID,
+			Title:       title,
+			Description: oldList.Description,
+		})
+		if err != nil {
+			return err
+		}
+
+		// The clone gets the same workflow and, like new todos, every todo
+		// starts in the first state that isn't done.
+		stateID := pgtype.Text{}
+		for _, oldState := range oldStates {
+			state, err := q.CreateListState(ctx, db.CreateListStateParams{
+				ID:       uuid.New().String(),
+				ListID:   list.ID,
+				Name:     oldState.Name,
+				Position: oldState.Position,
+				IsDone:   oldState.IsDone,
+			})
+			if err != nil {
+				return err
+			}
+			if !stateID.Valid && !state.IsDone {
+				stateID = pgtype.Text{String: state.ID, Valid: true}
+			}
+		}
+
+		newIds := make(map[string]string, len(oldTodos))
+		for _, oldTodo := range oldTodos {
+			newIds[oldTodo.ID] = uuid.New().String()
+			parentID := pgtype.Text{}
+			if oldTodo.ParentID.Valid {
+				parentID = pgtype.Text{String: newIds[oldTodo.ParentID.String], Valid: true}
+			}
+
+			todo, err := q.CreateTodo(ctx, db.CreateTodoParams{
+				ID:             newIds[oldTodo.ID],
+				ListID:         list.ID,
+				UserID:         reqUser.ID,
+				ParentID:       parentID,
+				Title:          oldTodo.Title,
+				Description:    oldTodo.Description,
+				CompleteBefore: oldTodo.CompleteBefore,
+				StateID:        stateID,
+			})
+			if err != nil {
+				return err
+			}
+			todos = append(todos, todo)
+		}
+
+		newFieldIds := make(map[string]string, len(oldFields))
+		for _, oldField := range oldFields {
+			field, err := q.CreateListField(ctx, db.CreateListFieldParams{
+				ID:        uuid.New().String(),
+				ListID:    list.ID,
+				Name:      oldField.Name,
+				FieldType: oldField.FieldType,
+				Options:   oldField.Options,
+				Position:  oldField.Position,
+			})
+			if err != nil {
+				return err
+			}
+			newFieldIds[oldField.ID] = field.ID
+		}
+		for _, oldValue := range oldValues {
+			_, err := q.UpsertTodoFieldValue(ctx, db.UpsertTodoFieldValueParams{
+				TodoID:  newIds[oldValue.TodoID],
+				FieldID: newFieldIds[oldValue.FieldID],
+				Value:   oldValue.Value,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to clone list", file, line, err, ctx)
+		return
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventCreate,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		&list,
+		&oldList,
+		logging.ObjectEventSubList,
+	)
+	ctx.JSON(201, gin.H{"status": "created", "list": listResponse(list, todos)})
+}
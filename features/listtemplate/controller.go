@@ -0,0 +1,130 @@
+package listtemplate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/util/mycontext"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TemplateController struct {
+	pool *pgxpool.Pool
+	db   *db.Queries
+	ctx  context.Context
+}
+
+func NewController(pool *pgxpool.Pool, db *db.Queries, ctx context.Context) *TemplateController {
+	return &TemplateController{pool: pool, db: db, ctx: ctx}
+}
+
+// Orders items so that every parent comes before its children. Items whose
+// parent is not in items are treated as roots.
+func orderParentsFirst[T any](
+	items []T,
+	getID func(T) string,
+	getParentID func(T) pgtype.Text,
+) []T {
+	ids := make(map[string]bool, len(items))
+	children := make(map[string][]T)
+	ordered := make([]T, 0, len(items))
+	for _, item := range items {
+		ids[getID(item)] = true
+	}
+	for _, item := range items {
+		parentID := getParentID(item)
+		if parentID.Valid && ids[parentID.String] {
+			children[parentID.String] = append(children[parentID.String], item)
+		} else {
+			ordered = append(ordered, item)
+		}
+	}
+	for i := 0; i < len(ordered); i++ {
+		ordered = append(ordered, children[getID(ordered[i])]...)
+	}
+	return ordered
+}
+
+// Checks that reqUser owns the list or that it is shared with them. Errors are
+// pushed to gin.Context and false is returned if access is not allowed.
+func (controller *TemplateController) canAccessList(reqUser *db.User, listID string, ctx *gin.Context) bool {
+	listIds, err := controller.db.GetListIdsAccessible(ctx, reqUser.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError(
+			"failed to get list accessible by user",
+			file,
+			line,
+			err,
+			ctx,
+		)
+		return false
+	}
+	if !slices.Contains(listIds, listID) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			listID,
+			reqUser.ID,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gin.ErrorTypePublic)
+		return false
+	}
+	return true
+}
+
+// Gets the template if reqUser is allowed to use it. Private templates are
+// only usable by their owner and admins. Errors are pushed to gin.Context and
+// nil is returned on failure.
+func (controller *TemplateController) getTemplate(
+	reqUser *db.User,
+	templateID string,
+	ctx *gin.Context,
+) *db.ListTemplate {
+	template, err := controller.db.GetListTemplate(ctx, templateID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return nil
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get template", file, line, err, ctx)
+		return nil
+	}
+
+	if !template.IsGlobal && template.UserID != reqUser.ID && !mycontext.HasAdminRights(reqUser, ctx) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			fmt.Sprintf("template: %v", templateID),
+			reqUser.ID,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gin.ErrorTypePublic)
+		return nil
+	}
+	return &template
+}
+
+func listResponse(list db.List, todos []db.Todo) map[string]any {
+	return map[string]any{
+		"id":          list.ID,
+		"user_id":     list.UserID,
+		"title":       list.Title,
+		"description": list.Description,
+		"created_at":  list.CreatedAt,
+		"updated_at":  list.UpdatedAt,
+		"todos":       todos,
+	}
+}
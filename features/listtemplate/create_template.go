@@ -0,0 +1,174 @@
+package listtemplate
+
+import (
+	"errors"
+	"runtime"
+	"time"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+	"go-todo/util/validate"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Saves a list with its todos as a template.
+func (controller *TemplateController) CreateTemplate(ctx *gin.Context) {
+	var payload *schemas.CreateTemplate
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	}
+
+	tokenUserId, tokenUserName, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	reqUser, err := database.GetUserById(controller.db, tokenUserId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			tokenUserName,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	if payload.IsGlobal && !mycontext.HasAdminRights(reqUser, ctx) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreMedium,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			"global template",
+			reqUser.ID,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	if ok := controller.canAccessList(reqUser, payload.ListID, ctx); !ok {
+		return
+	}
+
+	list, err := controller.db.GetList(ctx, payload.ListID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get list", file, line, err, ctx)
+		return
+	}
+
+	title := list.Title
+	description := list.Description
+	if payload.Title != nil {
+		title = *payload.Title
+	}
+	if payload.Description != nil {
+		description = pgtype.Text{String: *payload.Description, Valid: true}
+	}
+	if !validate.LengthTitle(title) {
+		ctx.Error(gterrors.NewGtValueError(title, "title too long"))
+		return
+	} else if !validate.LengthDescription(description.String) {
+		ctx.Error(gterrors.NewGtValueError(description.String, "description too long"))
+		return
+	}
+
+	anchor := list.CreatedAt.Time
+	if payload.Anchor != nil {
+		anchor = payload.Anchor.UTC()
+	}
+
+	todos, err := controller.db.GetTodosByList(ctx, list.ID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get todos", file, line, err, ctx)
+		return
+	}
+	todos = orderParentsFirst(
+		todos,
+		func(t db.Todo) string { return t.ID },
+		func(t db.Todo) pgtype.Text { return t.ParentID },
+	)
+
+	var template db.ListTemplate
+	templateTodos := make([]db.TemplateTodo, 0, len(todos))
+	err = database.WithTx(ctx, controller.pool, controller.db, func(q *db.Queries) error {
+		var err error
+		template, err = q.CreateListTemplate(ctx, db.CreateListTemplateParams{
+			ID:          uuid.New().String(),
+			UserID:      reqUser.ID,
+			Title:       title,
+			Description: description,
+			IsGlobal:    payload.IsGlobal,
+		})
+		if err != nil {
+			return err
+		}
+
+		newIds := make(map[string]string, len(todos))
+		for _, todo := range todos {
+			newIds[todo.ID] = uuid.New().String()
+			parentID := pgtype.Text{}
+			if todo.ParentID.Valid {
+				parentID = pgtype.Text{String: newIds[todo.ParentID.String], Valid: true}
+			}
+			dueOffset := pgtype.Int8{}
+			if todo.CompleteBefore.Valid {
+				dueOffset = pgtype.Int8{
+					Int64: int64(todo.CompleteBefore.Time.Sub(anchor) / time.Second),
+					Valid: true,
+				}
+			}
+
+			templateTodo, err := q.CreateTemplateTodo(ctx, db.CreateTemplateTodoParams{
+				ID:               newIds[todo.ID],
+				TemplateID:       template.ID,
+				ParentID:         parentID,
+				Title:            todo.Title,
+				Description:      todo.Description,
+				DueOffsetSeconds: dueOffset,
+			})
+			if err != nil {
+				return err
+			}
+			templateTodos = append(templateTodos, templateTodo)
+		}
+		return nil
+	})
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to create template", file, line, err, ctx)
+		return
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventCreate,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		&template,
+		nil,
+		logging.ObjectEventSubTemplate,
+	)
+	ctx.JSON(201, gin.H{
+		"status":   "created",
+		"template": template,
+		"todos":    templateTodos,
+	})
+}
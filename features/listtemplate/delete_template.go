@@ -0,0 +1,72 @@
+package listtemplate
+
+import (
+	"fmt"
+	"runtime"
+
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deletes a template. Only the owner or an admin can delete it.
+func (controller *TemplateController) DeleteTemplate(ctx *gin.Context) {
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	template := controller.getTemplate(reqUser, ctx.Param("templateID"), ctx)
+	if template == nil {
+		return
+	}
+	if template.UserID != reqUser.ID && !mycontext.HasAdminRights(reqUser, ctx) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			fmt.Sprintf("template: %v", template.ID),
+			reqUser.ID,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	rows, err := controller.db.DeleteListTemplate(ctx, template.ID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to delete template", file, line, err, ctx)
+		return
+	}
+
+	if rows != 0 {
+		logging.LogObjectEvent(
+			ctx.FullPath(),
+			ctx.ClientIP(),
+			logging.ObjectEventDelete,
+			reqUser,
+			mycontext.GetTokenAct(ctx),
+			"deleted",
+			template.ID,
+			logging.ObjectEventSubTemplate,
+		)
+	}
+	ctx.JSON(204, gin.H{})
+}
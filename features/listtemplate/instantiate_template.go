@@ -0,0 +1,147 @@
+package listtemplate
+
+import (
+	"runtime"
+	"time"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+	"go-todo/util/validate"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Creates a new list owned by the requester from a template. Due dates are
+// shifted from the anchor given in the payload.
+func (controller *TemplateController) InstantiateTemplate(ctx *gin.Context) {
+	var payload *schemas.InstantiateTemplate
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	}
+
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	template := controller.getTemplate(reqUser, ctx.Param("templateID"), ctx)
+	if template == nil {
+		return
+	}
+
+	title := template.Title
+	description := template.Description
+	if payload.Title != nil {
+		title = *payload.Title
+	}
+	if payload.Description != nil {
+		description = pgtype.Text{String: *payload.Description, Valid: true}
+	}
+	if !validate.LengthTitle(title) {
+		ctx.Error(gterrors.NewGtValueError(title, "title too long"))
+		return
+	} else if !validate.LengthDescription(description.String) {
+		ctx.Error(gterrors.NewGtValueError(description.String, "description too long"))
+		return
+	}
+
+	anchor := time.Now().UTC()
+	if payload.Anchor != nil {
+		anchor = payload.Anchor.UTC()
+	}
+
+	templateTodos, err := controller.db.GetTemplateTodosByTemplateId(ctx, template.ID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get template todos", file, line, err, ctx)
+		return
+	}
+	templateTodos = orderParentsFirst(
+		templateTodos,
+		func(t db.TemplateTodo) string { return t.ID },
+		func(t db.TemplateTodo) pgtype.Text { return t.ParentID },
+	)
+
+	var list db.List
+	todos := make([]db.Todo, 0, len(templateTodos))
+	err = database.WithTx(ctx, controller.pool, controller.db, func(q *db.Queries) error {
+		var err error
+		list, err = q.CreateList(ctx, db.CreateListParams{
+			ID:          uuid.New().String(),
+			UserID:      reqUser.ID,
+			Title:       title,
+			Description: description,
+		})
+		if err != nil {
+			return err
+		}
+
+		newIds := make(map[string]string, len(templateTodos))
+		for _, templateTodo := range templateTodos {
+			newIds[templateTodo.ID] = uuid.New().String()
+			parentID := pgtype.Text{}
+			if templateTodo.ParentID.Valid {
+				parentID = pgtype.Text{String: newIds[templateTodo.ParentID.String], Valid: true}
+			}
+			completeBefore := pgtype.Timestamp{}
+			if templateTodo.DueOffsetSeconds.Valid {
+				completeBefore = pgtype.Timestamp{
+					Time:  anchor.Add(time.Duration(templateTodo.DueOffsetSeconds.Int64) * time.Second),
+					Valid: true,
+				}
+			}
+
+			todo, err := q.CreateTodo(ctx, db.CreateTodoParams{
+				ID:             newIds[templateTodo.ID],
+				ListID:         list.ID,
+				UserID:         reqUser.ID,
+				ParentID:       parentID,
+				Title:          templateTodo.Title,
+				Description:    templateTodo.Description,
+				CompleteBefore: completeBefore,
+			})
+			if err != nil {
+				return err
+			}
+			todos = append(todos, todo)
+		}
+		return nil
+	})
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to instantiate template", file, line, err, ctx)
+		return
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventCreate,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		&list,
+		nil,
+		logging.ObjectEventSubList,
+	)
+	ctx.JSON(201, gin.H{"status": "created", "list": listResponse(list, todos)})
+}
@@ -0,0 +1,97 @@
+package listtemplate
+
+import (
+	"runtime"
+
+	"go-todo/logging"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Returns the requesters own templates and the global ones.
+func (controller *TemplateController) ReadTemplates(ctx *gin.Context) {
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	templates, err := controller.db.GetListTemplatesAccessible(ctx, reqUser.ID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get templates", file, line, err, ctx)
+		return
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventRead,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		templates,
+		nil,
+		logging.ObjectEventSubTemplate,
+	)
+	ctx.JSON(200, gin.H{"status": "ok", "templates": templates})
+}
+
+func (controller *TemplateController) ReadTemplate(ctx *gin.Context) {
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	template := controller.getTemplate(reqUser, ctx.Param("templateID"), ctx)
+	if template == nil {
+		return
+	}
+
+	todos, err := controller.db.GetTemplateTodosByTemplateId(ctx, template.ID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get template todos", file, line, err, ctx)
+		return
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventRead,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		template,
+		nil,
+		logging.ObjectEventSubTemplate,
+	)
+	ctx.JSON(200, gin.H{"status": "ok", "template": template, "todos": todos})
+}
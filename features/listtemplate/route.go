@@ -0,0 +1,29 @@
+package listtemplate
+
+import (
+	"go-todo/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TemplateRoutes struct {
+	templateController *TemplateController
+}
+
+func NewRoutes(templateController *TemplateController) *TemplateRoutes {
+	return &TemplateRoutes{templateController}
+}
+
+func (routes *TemplateRoutes) Register(rg *gin.RouterGroup) {
+	router := rg.Group("/template")
+
+	router.Use(middleware.TokenAuthMiddleware("templates"))
+
+	router.GET("/", routes.templateController.ReadTemplates)
+	router.GET("/:templateID", routes.templateController.ReadTemplate)
+	router.POST("/", routes.templateController.CreateTemplate)
+	router.POST("/:templateID/instantiate", routes.templateController.InstantiateTemplate)
+	router.DELETE("/:templateID", routes.templateController.DeleteTemplate)
+
+	rg.POST("/list/:listID/clone", middleware.TokenAuthMiddleware("lists"), routes.templateController.CloneList)
+}
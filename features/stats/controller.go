@@ -0,0 +1,162 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/schemas"
+	"go-todo/util/mycontext"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	bucketDaily  = "daily"
+	bucketWeekly = "weekly"
+)
+
+// Upper limit for the number of buckets returned in one response.
+const maxBuckets = 366
+
+type StatsController struct {
+	db  *db.Queries
+	ctx context.Context
+}
+
+func NewController(db *db.Queries, ctx context.Context) *StatsController {
+	return &StatsController{db: db, ctx: ctx}
+}
+
+// Truncates t to the start of its bucket. Weeks start on monday like
+// postgres date_trunc('week', ...).
+func truncateToBucket(t time.Time, bucket string) time.Time {
+	t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if bucket == bucketWeekly {
+		offset := (int(t.Weekday()) + 6) % 7
+		t = t.AddDate(0, 0, -offset)
+	}
+	return t
+}
+
+func nextBucket(t time.Time, bucket string) time.Time {
+	if bucket == bucketWeekly {
+		return t.AddDate(0, 0, 7)
+	}
+	return t.AddDate(0, 0, 1)
+}
+
+// Parses the query params 'bucket', 'from' and 'to'. Defaults to daily buckets
+// for the last 30 days. Errors are pushed to gin.Context and ok is false if
+// the params are invalid.
+func parseRangeParams(ctx *gin.Context) (bucket string, from, to time.Time, ok bool) {
+	bucket = ctx.DefaultQuery("bucket", bucketDaily)
+	if bucket != bucketDaily && bucket != bucketWeekly {
+		ctx.Error(gterrors.NewGtValueError(bucket, "bucket must be daily or weekly"))
+		return "", from, to, false
+	}
+
+	to = time.Now().UTC()
+	if toParam := ctx.Query("to"); toParam != "" {
+		parsed, err := time.Parse(time.DateOnly, toParam)
+		if err != nil {
+			ctx.Error(gterrors.NewGtValueError(toParam, "to must be YYYY-MM-DD"))
+			return "", from, to, false
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+	from = to.AddDate(0, 0, -30)
+	if fromParam := ctx.Query("from"); fromParam != "" {
+		parsed, err := time.Parse(time.DateOnly, fromParam)
+		if err != nil {
+			ctx.Error(gterrors.NewGtValueError(fromParam, "from must be YYYY-MM-DD"))
+			return "", from, to, false
+		}
+		from = parsed
+	}
+	from = truncateToBucket(from, bucket)
+
+	if !to.After(from) {
+		ctx.Error(gterrors.NewGtValueError(ctx.Query("to"), "to must be after from"))
+		return "", from, to, false
+	}
+	count := 0
+	for t := from; t.Before(to); t = nextBucket(t, bucket) {
+		count++
+	}
+	if count > maxBuckets {
+		ctx.Error(gterrors.NewGtValueError(
+			ctx.Query("from"),
+			fmt.Sprintf("range can contain at most %d buckets", maxBuckets),
+		))
+		return "", from, to, false
+	}
+	return bucket, from, to, true
+}
+
+// Builds the stats for lists with listIds. Errors are pushed to gin.Context
+// and nil is returned on failure.
+func (controller *StatsController) buildStats(
+	listIds []string,
+	bucket string,
+	from time.Time,
+	to time.Time,
+	ctx *gin.Context,
+) *schemas.ResponseStats {
+	counts, err := controller.db.GetTodoStatsByListIds(ctx, listIds)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get todo stats", file, line, err, ctx)
+		return nil
+	}
+
+	pgBucket := "day"
+	if bucket == bucketWeekly {
+		pgBucket = "week"
+	}
+	args := &db.GetCompletionBucketsByListIdsParams{
+		Bucket:     pgBucket,
+		ListIds:    listIds,
+		RangeStart: pgtype.Timestamp{Time: from, Valid: true},
+		RangeEnd:   pgtype.Timestamp{Time: to, Valid: true},
+	}
+	dbBuckets, err := controller.db.GetCompletionBucketsByListIds(ctx, *args)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get completion buckets", file, line, err, ctx)
+		return nil
+	}
+
+	bucketMap := make(map[int64]db.GetCompletionBucketsByListIdsRow, len(dbBuckets))
+	for _, dbBucket := range dbBuckets {
+		bucketMap[dbBucket.BucketStart.Time.Unix()] = dbBucket
+	}
+
+	// Fill the gaps so that every bucket in the range is present.
+	buckets := []schemas.ResponseStatsBucket{}
+	for t := from; t.Before(to); t = nextBucket(t, bucket) {
+		item := schemas.ResponseStatsBucket{Start: t}
+		if dbBucket, ok := bucketMap[t.Unix()]; ok {
+			item.CreatedCount = dbBucket.CreatedCount
+			item.CompletedCount = dbBucket.CompletedCount
+			if dbBucket.CreatedCount > 0 {
+				rate := float64(dbBucket.CompletedCount) / float64(dbBucket.CreatedCount)
+				item.CompletionRate = &rate
+			}
+		}
+		buckets = append(buckets, item)
+	}
+
+	return &schemas.ResponseStats{
+		OpenCount:            counts.OpenCount,
+		CompletedCount:       counts.CompletedCount,
+		OverdueCount:         counts.OverdueCount,
+		AvgCompletionSeconds: counts.AvgCompletionSeconds,
+		Bucket:               bucket,
+		Buckets:              buckets,
+	}
+}
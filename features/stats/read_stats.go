@@ -0,0 +1,159 @@
+package stats
+
+import (
+	"errors"
+	"runtime"
+	"slices"
+
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Returns stats over every list accessible by the requester.
+func (controller *StatsController) ReadUserStats(ctx *gin.Context) {
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	bucket, from, to, ok := parseRangeParams(ctx)
+	if !ok {
+		return
+	}
+
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	listIds, err := controller.db.GetListIdsAccessible(ctx, reqUser.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError(
+			"failed to get list accessible by user",
+			file,
+			line,
+			err,
+			ctx,
+		)
+		return
+	}
+
+	stats := controller.buildStats(listIds, bucket, from, to, ctx)
+	if stats == nil {
+		return
+	}
+
+	ctx.JSON(200, gin.H{"status": "ok", "stats": stats})
+}
+
+// Returns stats of a single list with a breakdown per collaborator.
+func (controller *StatsController) ReadListStats(ctx *gin.Context) {
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	bucket, from, to, ok := parseRangeParams(ctx)
+	if !ok {
+		return
+	}
+
+	listID := ctx.Param("listID")
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+	allowedIds, err := controller.db.GetListIdsAccessible(ctx, reqUser.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError(
+			"failed to get list accessible by user",
+			file,
+			line,
+			err,
+			ctx,
+		)
+		return
+	}
+	if !slices.Contains(allowedIds, listID) && !mycontext.HasAdminRights(reqUser, ctx) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			listID,
+			reqUser.ID,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	list, err := controller.db.GetList(ctx, listID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get list", file, line, err, ctx)
+		return
+	}
+
+	stats := controller.buildStats([]string{list.ID}, bucket, from, to, ctx)
+	if stats == nil {
+		return
+	}
+
+	userStats, err := controller.db.GetTodoStatsByUserForList(ctx, list.ID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get collaborator stats", file, line, err, ctx)
+		return
+	}
+	stats.Collaborators = make([]schemas.ResponseStatsCollaborator, 0, len(userStats))
+	for _, userStat := range userStats {
+		stats.Collaborators = append(stats.Collaborators, schemas.ResponseStatsCollaborator{
+			UserID:         userStat.UserID,
+			Username:       userStat.Username,
+			OpenCount:      userStat.OpenCount,
+			CompletedCount: userStat.CompletedCount,
+			OverdueCount:   userStat.OverdueCount,
+		})
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventRead,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		&list,
+		nil,
+		logging.ObjectEventSubList,
+	)
+	ctx.JSON(200, gin.H{"status": "ok", "stats": stats})
+}
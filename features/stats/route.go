@@ -0,0 +1,20 @@
+package stats
+
+import (
+	"go-todo/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StatsRoutes struct {
+	statsController *StatsController
+}
+
+func NewRoutes(statsController *StatsController) *StatsRoutes {
+	return &StatsRoutes{statsController}
+}
+
+func (routes *StatsRoutes) Register(rg *gin.RouterGroup) {
+	rg.GET("/stats", middleware.TokenAuthMiddleware("stats"), routes.statsController.ReadUserStats)
+	rg.GET("/list/:listID/stats", middleware.TokenAuthMiddleware("stats"), routes.statsController.ReadListStats)
+}
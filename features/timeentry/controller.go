@@ -0,0 +1,80 @@
+package timeentry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/util/mycontext"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type TimeEntryController struct {
+	db  *db.Queries
+	ctx context.Context
+}
+
+func NewController(db *db.Queries, ctx context.Context) *TimeEntryController {
+	return &TimeEntryController{db: db, ctx: ctx}
+}
+
+// Checks that reqUser owns the list or that it is shared with them. Errors are
+// pushed to gin.Context and false is returned if access is not allowed.
+func (controller *TimeEntryController) canAccessList(
+	reqUser *db.User,
+	listID string,
+	todoID string,
+	ctx *gin.Context,
+) bool {
+	listIds, err := controller.db.GetListIdsAccessible(ctx, reqUser.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError(
+			"failed to get list accessible by user",
+			file,
+			line,
+			err,
+			ctx,
+		)
+		return false
+	}
+	if !slices.Contains(listIds, listID) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			fmt.Sprintf("list: %v, todo: %v", listID, todoID),
+			reqUser.ID,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gin.ErrorTypePublic)
+		return false
+	}
+	return true
+}
+
+// Gets the todo from the list. Errors are pushed to gin.Context and nil is
+// returned if the todo could not be found.
+func (controller *TimeEntryController) getTodo(listID, todoID string, ctx *gin.Context) *db.Todo {
+	args := &db.GetTodoByIdWithListIdParams{
+		ID:     todoID,
+		ListID: listID,
+	}
+	todo, err := controller.db.GetTodoByIdWithListId(ctx, *args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return nil
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get todo", file, line, err, ctx)
+		return nil
+	}
+	return &todo
+}
@@ -0,0 +1,98 @@
+package timeentry
+
+import (
+	"runtime"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+	"go-todo/util/validate"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Creates a manual time entry for the todo.
+func (controller *TimeEntryController) CreateEntry(ctx *gin.Context) {
+	payload := &schemas.CreateTimeEntry{}
+	note := ""
+
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	}
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+	listID := ctx.Param("listID")
+	todoID := ctx.Param("todoID")
+
+	if !payload.EndedAt.After(payload.StartedAt) {
+		ctx.Error(gterrors.NewGtValueError(
+			payload.EndedAt.String(),
+			"ended_at must be after started_at",
+		))
+		return
+	}
+	if payload.Note != nil {
+		if ok := validate.LengthDescription(*payload.Note); !ok {
+			ctx.Error(gterrors.NewGtValueError(*payload.Note, "note too long"))
+			return
+		}
+		note = *payload.Note
+	}
+
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	if ok := controller.canAccessList(reqUser, listID, todoID, ctx); !ok {
+		return
+	}
+	todo := controller.getTodo(listID, todoID, ctx)
+	if todo == nil {
+		return
+	}
+
+	args := &db.CreateTimeEntryParams{
+		ID:        uuid.New().String(),
+		TodoID:    todo.ID,
+		UserID:    reqUser.ID,
+		StartedAt: pgtype.Timestamp{Time: payload.StartedAt.UTC(), Valid: true},
+		EndedAt:   pgtype.Timestamp{Time: payload.EndedAt.UTC(), Valid: true},
+		Note:      pgtype.Text{String: note, Valid: payload.Note != nil},
+	}
+
+	entry, err := controller.db.CreateTimeEntry(ctx, *args)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to create time entry", file, line, err, ctx)
+		return
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventCreate,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		&entry,
+		nil,
+		logging.ObjectEventSubTimeEntry,
+	)
+	ctx.JSON(201, gin.H{"status": "created", "time_entry": entry})
+}
@@ -0,0 +1,100 @@
+package timeentry
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Deletes a time entry. Only the owner of the entry can delete it.
+func (controller *TimeEntryController) DeleteEntry(ctx *gin.Context) {
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	listID := ctx.Param("listID")
+	todoID := ctx.Param("todoID")
+	entryID := ctx.Param("entryID")
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	if ok := controller.canAccessList(reqUser, listID, todoID, ctx); !ok {
+		return
+	}
+	todo := controller.getTodo(listID, todoID, ctx)
+	if todo == nil {
+		return
+	}
+
+	entry, err := controller.db.GetTimeEntryById(ctx, entryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get time entry", file, line, err, ctx)
+		return
+	}
+	if entry.TodoID != todo.ID {
+		ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+		return
+	}
+	if entry.UserID != reqUser.ID {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			fmt.Sprintf("time entry: %v", entryID),
+			reqUser.ID,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	args := &db.DeleteTimeEntryByIdWithUserIdParams{
+		ID:     entry.ID,
+		UserID: reqUser.ID,
+	}
+	rows, err := controller.db.DeleteTimeEntryByIdWithUserId(ctx, *args)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to delete time entry", file, line, err, ctx)
+		return
+	}
+
+	if rows != 0 {
+		logging.LogObjectEvent(
+			ctx.FullPath(),
+			ctx.ClientIP(),
+			logging.ObjectEventDelete,
+			reqUser,
+			mycontext.GetTokenAct(ctx),
+			"deleted",
+			entry.ID,
+			logging.ObjectEventSubTimeEntry,
+		)
+	}
+	ctx.JSON(204, gin.H{})
+}
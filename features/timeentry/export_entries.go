@@ -0,0 +1,144 @@
+package timeentry
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"runtime"
+	"strconv"
+	"time"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	formatJson = "json"
+	formatCsv  = "csv"
+)
+
+// Parses time given either as RFC3339 or as a plain date.
+func parseTimeParam(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// Exports the requesters time entries that started in the range [from, to).
+// Query param 'format' can be either json (default) or csv.
+func (controller *TimeEntryController) ExportEntries(ctx *gin.Context) {
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	fromParam := ctx.Query("from")
+	toParam := ctx.Query("to")
+	format := ctx.DefaultQuery("format", formatJson)
+	from, err := parseTimeParam(fromParam)
+	if err != nil {
+		ctx.Error(gterrors.NewGtValueError(fromParam, "from must be RFC3339 or YYYY-MM-DD"))
+		return
+	}
+	to, err := parseTimeParam(toParam)
+	if err != nil {
+		ctx.Error(gterrors.NewGtValueError(toParam, "to must be RFC3339 or YYYY-MM-DD"))
+		return
+	}
+	if !to.After(from) {
+		ctx.Error(gterrors.NewGtValueError(toParam, "to must be after from"))
+		return
+	}
+	if format != formatJson && format != formatCsv {
+		ctx.Error(gterrors.NewGtValueError(format, "format must be json or csv"))
+		return
+	}
+
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	args := &db.GetTimeEntriesByUserIdInRangeParams{
+		UserID:     reqUser.ID,
+		RangeStart: pgtype.Timestamp{Time: from, Valid: true},
+		RangeEnd:   pgtype.Timestamp{Time: to, Valid: true},
+	}
+	entries, err := controller.db.GetTimeEntriesByUserIdInRange(ctx, *args)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get time entries", file, line, err, ctx)
+		return
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventRead,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		entries,
+		nil,
+		logging.ObjectEventSubTimeEntry,
+	)
+
+	if format == formatJson {
+		ctx.JSON(200, gin.H{"status": "ok", "time_entries": entries})
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+	writer.Write([]string{"id", "todo_id", "started_at", "ended_at", "seconds", "note"})
+	for _, entry := range entries {
+		endedAt := ""
+		seconds := ""
+		if entry.EndedAt.Valid {
+			endedAt = entry.EndedAt.Time.Format(time.RFC3339)
+			seconds = strconv.FormatInt(
+				int64(entry.EndedAt.Time.Sub(entry.StartedAt.Time).Seconds()),
+				10,
+			)
+		}
+		writer.Write([]string{
+			entry.ID,
+			entry.TodoID,
+			entry.StartedAt.Time.Format(time.RFC3339),
+			endedAt,
+			seconds,
+			entry.Note.String,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to write csv", file, line, err, ctx)
+		return
+	}
+
+	ctx.Header(
+		"Content-Disposition",
+		fmt.Sprintf(
+			"attachment; filename=\"time-entries-%v-%v.csv\"",
+			from.Format(time.DateOnly),
+			to.Format(time.DateOnly),
+		),
+	)
+	ctx.Data(200, "text/csv", buf.Bytes())
+}
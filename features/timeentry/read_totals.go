@@ -0,0 +1,166 @@
+package timeentry
+
+import (
+	"errors"
+	"runtime"
+
+	"go-todo/logging"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Returns all time entries of the todo and their total duration. Entries of
+// every collaborator are visible.
+func (controller *TimeEntryController) ReadTodoEntries(ctx *gin.Context) {
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	listID := ctx.Param("listID")
+	todoID := ctx.Param("todoID")
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	if ok := controller.canAccessList(reqUser, listID, todoID, ctx); !ok {
+		return
+	}
+	todo := controller.getTodo(listID, todoID, ctx)
+	if todo == nil {
+		return
+	}
+
+	entries, err := controller.db.GetTimeEntriesByTodoId(ctx, todo.ID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get time entries", file, line, err, ctx)
+		return
+	}
+	total, err := controller.db.GetTimeTotalByTodoId(ctx, todo.ID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get time total", file, line, err, ctx)
+		return
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventRead,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		entries,
+		nil,
+		logging.ObjectEventSubTimeEntry,
+	)
+	ctx.JSON(200, gin.H{
+		"status":        "ok",
+		"total_seconds": total,
+		"time_entries":  entries,
+	})
+}
+
+// Returns the total tracked time of the list and a breakdown per todo.
+func (controller *TimeEntryController) ReadListTotals(ctx *gin.Context) {
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	listID := ctx.Param("listID")
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	if ok := controller.canAccessList(reqUser, listID, "", ctx); !ok {
+		return
+	}
+
+	todoTotals, err := controller.db.GetTimeTotalsByListId(ctx, listID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get time totals", file, line, err, ctx)
+		return
+	}
+
+	var total int64
+	for _, todoTotal := range todoTotals {
+		total += todoTotal.Seconds
+	}
+
+	ctx.JSON(200, gin.H{
+		"status":        "ok",
+		"total_seconds": total,
+		"todos":         todoTotals,
+	})
+}
+
+// Returns the total tracked time of the requester and the running timer if
+// there is one.
+func (controller *TimeEntryController) ReadUserTotal(ctx *gin.Context) {
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	total, err := controller.db.GetTimeTotalByUserId(ctx, reqUser.ID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get time total", file, line, err, ctx)
+		return
+	}
+
+	var running any
+	runningEntry, err := controller.db.GetRunningTimeEntryByUserId(ctx, reqUser.ID)
+	if err == nil {
+		running = runningEntry
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get running timer", file, line, err, ctx)
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"status":        "ok",
+		"total_seconds": total,
+		"running":       running,
+	})
+}
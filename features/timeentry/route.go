@@ -0,0 +1,33 @@
+package timeentry
+
+import (
+	"go-todo/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TimeEntryRoutes struct {
+	timeEntryController *TimeEntryController
+}
+
+func NewRoutes(timeEntryController *TimeEntryController) *TimeEntryRoutes {
+	return &TimeEntryRoutes{timeEntryController}
+}
+
+func (routes *TimeEntryRoutes) Register(rg *gin.RouterGroup) {
+	listRouter := rg.Group("/list/:listID")
+	listRouter.Use(middleware.TokenAuthMiddleware("time"))
+	listRouter.GET("/time", routes.timeEntryController.ReadListTotals)
+
+	todoRouter := listRouter.Group("/todo/:todoID/time")
+	todoRouter.GET("/", routes.timeEntryController.ReadTodoEntries)
+	todoRouter.POST("/", routes.timeEntryController.CreateEntry)
+	todoRouter.POST("/start", routes.timeEntryController.StartTimer)
+	todoRouter.POST("/stop", routes.timeEntryController.StopTimer)
+	todoRouter.DELETE("/:entryID", routes.timeEntryController.DeleteEntry)
+
+	userRouter := rg.Group("/time")
+	userRouter.Use(middleware.TokenAuthMiddleware("time"))
+	userRouter.GET("/", routes.timeEntryController.ReadUserTotal)
+	userRouter.GET("/export", routes.timeEntryController.ExportEntries)
+}
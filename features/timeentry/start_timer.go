@@ -0,0 +1,84 @@
+package timeentry
+
+import (
+	"errors"
+	"runtime"
+	"time"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (controller *TimeEntryController) StartTimer(ctx *gin.Context) {
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	listID := ctx.Param("listID")
+	todoID := ctx.Param("todoID")
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	if ok := controller.canAccessList(reqUser, listID, todoID, ctx); !ok {
+		return
+	}
+	todo := controller.getTodo(listID, todoID, ctx)
+	if todo == nil {
+		return
+	}
+
+	args := &db.CreateTimeEntryParams{
+		ID:        uuid.New().String(),
+		TodoID:    todo.ID,
+		UserID:    reqUser.ID,
+		StartedAt: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+		EndedAt:   pgtype.Timestamp{Valid: false},
+		Note:      pgtype.Text{Valid: false},
+	}
+
+	entry, err := controller.db.CreateTimeEntry(ctx, *args)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		errMessage := "failed to start timer"
+		// Unique index allows only one running timer per user.
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			ctx.Error(gterrors.ErrTimerRunning).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError(errMessage, file, line, err, ctx)
+		return
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventCreate,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		&entry,
+		nil,
+		logging.ObjectEventSubTimeEntry,
+	)
+	ctx.JSON(201, gin.H{"status": "created", "time_entry": entry})
+}
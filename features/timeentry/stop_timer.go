@@ -0,0 +1,91 @@
+package timeentry
+
+import (
+	"errors"
+	"runtime"
+	"time"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (controller *TimeEntryController) StopTimer(ctx *gin.Context) {
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	listID := ctx.Param("listID")
+	todoID := ctx.Param("todoID")
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	if ok := controller.canAccessList(reqUser, listID, todoID, ctx); !ok {
+		return
+	}
+	todo := controller.getTodo(listID, todoID, ctx)
+	if todo == nil {
+		return
+	}
+
+	runningEntry, err := controller.db.GetRunningTimeEntryByUserId(ctx, reqUser.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get running timer", file, line, err, ctx)
+		return
+	}
+	if runningEntry.TodoID != todo.ID {
+		ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	args := &db.StopTimeEntryParams{
+		ID:      runningEntry.ID,
+		EndedAt: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	}
+	entry, err := controller.db.StopTimeEntry(ctx, *args)
+	if err != nil {
+		// Timer was stopped by a concurrent request.
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to stop timer", file, line, err, ctx)
+		return
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventUpdate,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		&entry,
+		&runningEntry,
+		logging.ObjectEventSubTimeEntry,
+	)
+	ctx.JSON(200, gin.H{"status": "ok", "time_entry": entry})
+}
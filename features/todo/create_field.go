@@ -0,0 +1,136 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+	"go-todo/util/validate"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Adds a custom field to the list. Fields are appended to the end unless
+// position is given.
+func (controller *TodoController) CreateField(ctx *gin.Context) {
+	var payload *schemas.CreateListField
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	}
+
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	if ok := validate.LengthTitle(payload.Name); !ok {
+		ctx.Error(gterrors.NewGtValueError(payload.Name, "name too long"))
+		return
+	}
+	if !validFieldType(payload.FieldType) {
+		ctx.Error(gterrors.NewGtValueError(payload.FieldType, "unknown field type"))
+		return
+	}
+	if detail := checkFieldOptions(payload.FieldType, payload.Options); detail != "" {
+		ctx.Error(gterrors.NewGtValueError(fmt.Sprint(payload.Options), detail))
+		return
+	}
+	options := payload.Options
+	if options == nil {
+		options = []string{}
+	}
+
+	listID := ctx.Param("listID")
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	list, err := controller.db.GetList(ctx, listID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get list", file, line, err, ctx)
+		return
+	}
+	if list.UserID != reqUser.ID && !mycontext.HasAdminRights(reqUser, ctx) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			fmt.Sprintf("listID: %v", listID),
+			reqUser.ID,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	var position int32
+	if payload.Position != nil {
+		position = *payload.Position
+	} else {
+		fields, err := controller.db.GetListFieldsByListId(ctx, list.ID)
+		if err != nil {
+			_, file, line, _ := runtime.Caller(0)
+			mycontext.CtxAddGtInternalError("failed to get fields", file, line, err, ctx)
+			return
+		}
+		if len(fields) != 0 {
+			position = fields[len(fields)-1].Position + 1
+		}
+	}
+
+	args := &db.CreateListFieldParams{
+		ID:        uuid.New().String(),
+		ListID:    list.ID,
+		Name:      payload.Name,
+		FieldType: payload.FieldType,
+		Options:   options,
+		Position:  position,
+	}
+
+	field, err := controller.db.CreateListField(ctx, *args)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			ctx.Error(gterrors.ErrUniqueViolation).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to create field", file, line, err, ctx)
+		return
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventCreate,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		&field,
+		nil,
+		logging.ObjectEventSubField,
+	)
+	ctx.JSON(201, gin.H{"status": "created", "field": field})
+}
@@ -101,6 +101,7 @@ func (controller *TodoController) CreateList(ctx *gin.Context) {
 		ctx.ClientIP(),
 		logging.ObjectEventCreate,
 		&reqUser,
+		mycontext.GetTokenAct(ctx),
 		&list,
 		nil,
 		logging.ObjectEventSubList,
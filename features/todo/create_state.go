@@ -0,0 +1,123 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+	"go-todo/util/validate"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Adds a workflow state to the list. States are appended to the end unless
+// position is given.
+func (controller *TodoController) CreateState(ctx *gin.Context) {
+	var payload *schemas.CreateListState
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	}
+
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	if ok := validate.LengthTitle(payload.Name); !ok {
+		ctx.Error(gterrors.NewGtValueError(payload.Name, "name too long"))
+		return
+	}
+
+	listID := ctx.Param("listID")
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	list, err := controller.db.GetList(ctx, listID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get list", file, line, err, ctx)
+		return
+	}
+	if list.UserID != reqUser.ID && !mycontext.HasAdminRights(reqUser, ctx) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			fmt.Sprintf("listID: %v", listID),
+			reqUser.ID,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	var position int32
+	if payload.Position != nil {
+		position = *payload.Position
+	} else {
+		states, err := controller.db.GetListStatesByListId(ctx, list.ID)
+		if err != nil {
+			_, file, line, _ := runtime.Caller(0)
+			mycontext.CtxAddGtInternalError("failed to get states", file, line, err, ctx)
+			return
+		}
+		if len(states) != 0 {
+			position = states[len(states)-1].Position + 1
+		}
+	}
+
+	args := &db.CreateListStateParams{
+		ID:       uuid.New().String(),
+		ListID:   list.ID,
+		Name:     payload.Name,
+		Position: position,
+		IsDone:   payload.IsDone,
+	}
+
+	state, err := controller.db.CreateListState(ctx, *args)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			ctx.Error(gterrors.ErrUniqueViolation).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to create state", file, line, err, ctx)
+		return
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventCreate,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		&state,
+		nil,
+		logging.ObjectEventSubState,
+	)
+	ctx.JSON(201, gin.H{"status": "created", "state": state})
+}
@@ -94,6 +94,22 @@ func (controller *TodoController) CreateTodo(ctx *gin.Context) {
 		completeBefore = time.Date(1970, 0o1, 0o1, 0o0, 0o0, 0o0, 0o0, time.UTC)
 	}
 
+	// New todos start in the first state that isn't done, if the list has
+	// states at all.
+	states, err := controller.db.GetListStatesByListId(ctx, listID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get states", file, line, err, ctx)
+		return
+	}
+	stateID := pgtype.Text{}
+	for _, state := range states {
+		if !state.IsDone {
+			stateID = pgtype.Text{String: state.ID, Valid: true}
+			break
+		}
+	}
+
 	args := &db.CreateTodoParams{
 		ID:             uuid.New().String(),
 		ListID:         listID,
@@ -102,6 +118,7 @@ func (controller *TodoController) CreateTodo(ctx *gin.Context) {
 		Description:    pgtype.Text{String: description, Valid: payload.Description != nil},
 		ParentID:       pgtype.Text{String: parentID, Valid: payload.ParentID != nil},
 		CompleteBefore: pgtype.Timestamp{Time: completeBefore, Valid: payload.CompleteBefore != nil},
+		StateID:        stateID,
 	}
 
 	todo, err := controller.db.CreateTodo(ctx, *args)
@@ -122,6 +139,7 @@ func (controller *TodoController) CreateTodo(ctx *gin.Context) {
 		ctx.ClientIP(),
 		logging.ObjectEventCreate,
 		reqUser,
+		mycontext.GetTokenAct(ctx),
 		&todo,
 		nil,
 		logging.ObjectEventSubTodo,
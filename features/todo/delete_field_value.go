@@ -0,0 +1,101 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Clears the value of a custom field on a todo.
+func (controller *TodoController) DeleteTodoFieldValue(ctx *gin.Context) {
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+	listID := ctx.Param("listID")
+	todoID := ctx.Param("todoID")
+	fieldID := ctx.Param("fieldID")
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	listIds, err := controller.db.GetListIdsAccessible(ctx, reqUser.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError(
+			"failed to get list accessible by user",
+			file,
+			line,
+			err,
+			ctx,
+		)
+		return
+	}
+	if !slices.Contains(listIds, listID) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			fmt.Sprintf("list: %v, todo: %v", listID, todoID),
+			reqUser.ID,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	todo, err := controller.db.GetTodoByIdWithListId(ctx, db.GetTodoByIdWithListIdParams{ID: todoID, ListID: listID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get todo", file, line, err, ctx)
+		return
+	}
+
+	args := &db.DeleteTodoFieldValueParams{
+		TodoID:  todo.ID,
+		FieldID: fieldID,
+	}
+	rows, err := controller.db.DeleteTodoFieldValue(ctx, *args)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to delete field value", file, line, err, ctx)
+		return
+	}
+
+	if rows != 0 {
+		logging.LogObjectEvent(
+			ctx.FullPath(),
+			ctx.ClientIP(),
+			logging.ObjectEventDelete,
+			reqUser,
+			mycontext.GetTokenAct(ctx),
+			"deleted",
+			fmt.Sprintf("todo: %v, field: %v", todo.ID, fieldID),
+			logging.ObjectEventSubField,
+		)
+	}
+	ctx.JSON(204, gin.H{})
+}
@@ -46,7 +46,7 @@ func (controller *TodoController) DeleteList(ctx *gin.Context) {
 		return
 	}
 
-	if listDeleted.UserID != reqUser.ID && !reqUser.IsAdmin {
+	if listDeleted.UserID != reqUser.ID && !mycontext.HasAdminRights(reqUser, ctx) {
 		logging.LogSecurityEvent(
 			logging.SecurityScoreLow,
 			logging.SecurityEventForbiddenAction,
@@ -77,6 +77,7 @@ func (controller *TodoController) DeleteList(ctx *gin.Context) {
 			ctx.ClientIP(),
 			logging.ObjectEventDelete,
 			reqUser,
+			mycontext.GetTokenAct(ctx),
 			"deleted",
 			listDeleted.ID,
 			logging.ObjectEventSubList,
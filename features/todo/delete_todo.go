@@ -83,6 +83,7 @@ func (controller *TodoController) DeleteTodo(ctx *gin.Context) {
 			ctx.ClientIP(),
 			logging.ObjectEventDelete,
 			reqUser,
+			mycontext.GetTokenAct(ctx),
 			"deleted",
 			todoID,
 			logging.ObjectEventSubTodo,
@@ -0,0 +1,155 @@
+package todo
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"time"
+
+	db "go-todo/db/sqlc"
+	"go-todo/util/validate"
+)
+
+const (
+	FieldTypeText     = "text"
+	FieldTypeNumber   = "number"
+	FieldTypeDate     = "date"
+	FieldTypeSelect   = "select"
+	FieldTypeCheckbox = "checkbox"
+)
+
+const fieldDateLayout = time.DateOnly
+
+// Todo with the values of the list's custom fields keyed by field name.
+type todoWithFields struct {
+	db.Todo
+	Fields map[string]any `json:"fields"`
+}
+
+func validFieldType(fieldType string) bool {
+	switch fieldType {
+	case FieldTypeText, FieldTypeNumber, FieldTypeDate, FieldTypeSelect, FieldTypeCheckbox:
+		return true
+	}
+	return false
+}
+
+// Returns a description of what is wrong with the options, or an empty string
+// if they are fine for the field type.
+func checkFieldOptions(fieldType string, options []string) string {
+	if fieldType != FieldTypeSelect {
+		if len(options) != 0 {
+			return "options are only allowed on select fields"
+		}
+		return ""
+	}
+	if len(options) == 0 {
+		return "select field needs options"
+	}
+	for i, option := range options {
+		if option == "" || !validate.LengthTitle(option) {
+			return "invalid option"
+		}
+		if slices.Contains(options[:i], option) {
+			return "duplicate option"
+		}
+	}
+	return ""
+}
+
+// Validates a JSON value against the field and returns it in the form it is
+// stored in the database.
+func encodeFieldValue(field db.ListField, value any) (string, bool) {
+	switch field.FieldType {
+	case FieldTypeText:
+		s, ok := value.(string)
+		if !ok || !validate.LengthDescription(s) {
+			return "", false
+		}
+		return s, true
+	case FieldTypeNumber:
+		f, ok := value.(float64)
+		if !ok {
+			return "", false
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), true
+	case FieldTypeDate:
+		s, ok := value.(string)
+		if !ok {
+			return "", false
+		}
+		t, err := time.Parse(fieldDateLayout, s)
+		if err != nil {
+			return "", false
+		}
+		return t.Format(fieldDateLayout), true
+	case FieldTypeSelect:
+		s, ok := value.(string)
+		if !ok || !slices.Contains(field.Options, s) {
+			return "", false
+		}
+		return s, true
+	case FieldTypeCheckbox:
+		b, ok := value.(bool)
+		if !ok {
+			return "", false
+		}
+		return strconv.FormatBool(b), true
+	}
+	return "", false
+}
+
+// Same as encodeFieldValue but for values given as query parameters.
+func encodeFieldQuery(field db.ListField, value string) (string, bool) {
+	switch field.FieldType {
+	case FieldTypeNumber:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", false
+		}
+		return encodeFieldValue(field, f)
+	case FieldTypeCheckbox:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", false
+		}
+		return encodeFieldValue(field, b)
+	}
+	return encodeFieldValue(field, value)
+}
+
+// Converts a stored value back to the JSON type of the field.
+func decodeFieldValue(fieldType, value string) any {
+	switch fieldType {
+	case FieldTypeNumber:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case FieldTypeCheckbox:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// Compares two stored values of the field type. Dates are stored as
+// YYYY-MM-DD so they sort as strings.
+func compareFieldValues(fieldType, a, b string) int {
+	switch fieldType {
+	case FieldTypeNumber:
+		fa, _ := strconv.ParseFloat(a, 64)
+		fb, _ := strconv.ParseFloat(b, 64)
+		return cmp.Compare(fa, fb)
+	case FieldTypeCheckbox:
+		ba, _ := strconv.ParseBool(a)
+		bb, _ := strconv.ParseBool(b)
+		if ba == bb {
+			return 0
+		} else if bb {
+			return -1
+		}
+		return 1
+	}
+	return cmp.Compare(a, b)
+}
@@ -5,6 +5,7 @@ import (
 	"runtime"
 	"slices"
 
+	db "go-todo/db/sqlc"
 	"go-todo/gterrors"
 	"go-todo/logging"
 	"go-todo/util/database"
@@ -14,6 +15,9 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+// Returns the list with its states, custom fields and todos. Todos can be
+// filtered by custom field values with filter[<field>]=<value> and sorted with
+// sort=<field>&order=asc|desc. Todos without a value are sorted last.
 func (controller *TodoController) ReadListWithTodos(ctx *gin.Context) {
 	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
 	if err != nil {
@@ -46,7 +50,7 @@ func (controller *TodoController) ReadListWithTodos(ctx *gin.Context) {
 		)
 		return
 	}
-	if !slices.Contains(allowedIds, listID) && !reqUser.IsAdmin {
+	if !slices.Contains(allowedIds, listID) && !mycontext.HasAdminRights(reqUser, ctx) {
 		logging.LogSecurityEvent(
 			logging.SecurityScoreLow,
 			logging.SecurityEventForbiddenAction,
@@ -72,6 +76,96 @@ func (controller *TodoController) ReadListWithTodos(ctx *gin.Context) {
 		return
 	}
 
+	states, err := controller.db.GetListStatesByListId(ctx, listID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get states", file, line, err, ctx)
+		return
+	}
+
+	fields, err := controller.db.GetListFieldsByListId(ctx, listID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get fields", file, line, err, ctx)
+		return
+	}
+	values, err := controller.db.GetTodoFieldValuesByListId(ctx, listID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get field values", file, line, err, ctx)
+		return
+	}
+
+	fieldsById := make(map[string]db.ListField, len(fields))
+	fieldsByName := make(map[string]db.ListField, len(fields))
+	for _, field := range fields {
+		fieldsById[field.ID] = field
+		fieldsByName[field.Name] = field
+	}
+	// todo id -> field name -> stored value
+	todoValues := make(map[string]map[string]string, len(todos))
+	for _, value := range values {
+		if todoValues[value.TodoID] == nil {
+			todoValues[value.TodoID] = map[string]string{}
+		}
+		todoValues[value.TodoID][fieldsById[value.FieldID].Name] = value.Value
+	}
+
+	for name, filter := range ctx.QueryMap("filter") {
+		field, ok := fieldsByName[name]
+		if !ok {
+			ctx.Error(gterrors.NewGtValueError(name, "unknown field"))
+			return
+		}
+		want, ok := encodeFieldQuery(field, filter)
+		if !ok {
+			ctx.Error(gterrors.NewGtValueError(filter, "invalid "+field.FieldType+" value"))
+			return
+		}
+		todos = slices.DeleteFunc(todos, func(t db.Todo) bool {
+			got, ok := todoValues[t.ID][name]
+			return !ok || compareFieldValues(field.FieldType, got, want) != 0
+		})
+	}
+
+	if sortBy := ctx.Query("sort"); sortBy != "" {
+		field, ok := fieldsByName[sortBy]
+		if !ok {
+			ctx.Error(gterrors.NewGtValueError(sortBy, "unknown field"))
+			return
+		}
+		order := ctx.DefaultQuery("order", "asc")
+		if order != "asc" && order != "desc" {
+			ctx.Error(gterrors.NewGtValueError(order, "order must be asc or desc"))
+			return
+		}
+		slices.SortStableFunc(todos, func(a, b db.Todo) int {
+			va, okA := todoValues[a.ID][sortBy]
+			vb, okB := todoValues[b.ID][sortBy]
+			switch {
+			case !okA && !okB:
+				return 0
+			case !okA:
+				return 1
+			case !okB:
+				return -1
+			}
+			if order == "desc" {
+				return compareFieldValues(field.FieldType, vb, va)
+			}
+			return compareFieldValues(field.FieldType, va, vb)
+		})
+	}
+
+	todosWithFields := make([]todoWithFields, 0, len(todos))
+	for _, todo := range todos {
+		todoFields := make(map[string]any, len(todoValues[todo.ID]))
+		for name, value := range todoValues[todo.ID] {
+			todoFields[name] = decodeFieldValue(fieldsByName[name].FieldType, value)
+		}
+		todosWithFields = append(todosWithFields, todoWithFields{Todo: todo, Fields: todoFields})
+	}
+
 	response := map[string]any{
 		"id":          list.ID,
 		"user_id":     list.UserID,
@@ -79,7 +173,9 @@ func (controller *TodoController) ReadListWithTodos(ctx *gin.Context) {
 		"description": list.Description,
 		"created_at":  list.CreatedAt,
 		"updated_at":  list.UpdatedAt,
-		"todos":       todos,
+		"states":      states,
+		"fields":      fields,
+		"todos":       todosWithFields,
 	}
 
 	logging.LogObjectEvent(
@@ -87,6 +183,7 @@ func (controller *TodoController) ReadListWithTodos(ctx *gin.Context) {
 		ctx.ClientIP(),
 		logging.ObjectEventRead,
 		reqUser,
+		mycontext.GetTokenAct(ctx),
 		&list,
 		nil,
 		logging.ObjectEventSubList,
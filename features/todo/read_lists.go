@@ -54,7 +54,7 @@ func (controller *TodoController) ReadLists(ctx *gin.Context) {
 		return
 	}
 
-	if show == admin && !reqUser.IsAdmin {
+	if show == admin && !mycontext.HasAdminRights(reqUser, ctx) {
 		logging.LogSecurityEvent(
 			logging.SecurityScoreLow,
 			logging.SecurityEventForbiddenAction,
@@ -125,6 +125,7 @@ func (controller *TodoController) ReadLists(ctx *gin.Context) {
 		ctx.ClientIP(),
 		logging.ObjectEventRead,
 		reqUser,
+		mycontext.GetTokenAct(ctx),
 		*lists,
 		nil,
 		logging.ObjectEventSubList,
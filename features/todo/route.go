@@ -17,18 +17,32 @@ func NewRoutes(todoController *TodoController) *TodoRoutes {
 func (routes *TodoRoutes) Register(rg *gin.RouterGroup) {
 	router := rg.Group("/list")
 
-	router.Use(middleware.JwtAuthMiddleware())
-
-	router.GET("/", routes.todoController.ReadLists)
-	router.GET("/:listID", routes.todoController.ReadListWithTodos)
-	router.POST("/", routes.todoController.CreateList)
-	router.PATCH("/:listID", routes.todoController.UpdateList)
-	router.DELETE("/:listID", routes.todoController.DeleteList)
-
-	todoRouter := router.Group("/:listID/todo")
+	listRouter := router.Group("", middleware.TokenAuthMiddleware("lists"))
+	listRouter.GET("/", routes.todoController.ReadLists)
+	listRouter.GET("/:listID", routes.todoController.ReadListWithTodos)
+	listRouter.POST("/", routes.todoController.CreateList)
+	listRouter.PATCH("/:listID", routes.todoController.UpdateList)
+	listRouter.DELETE("/:listID", routes.todoController.DeleteList)
+
+	todoRouter := router.Group("/:listID/todo", middleware.TokenAuthMiddleware("todos"))
 	todoRouter.POST("/", routes.todoController.CreateTodo)
 	todoRouter.PATCH("/:todoID", routes.todoController.UpdateTodo)
 	todoRouter.DELETE("/:todoID", routes.todoController.DeleteTodo)
+	todoRouter.POST("/:todoID/transition", routes.todoController.TransitionTodo)
+	todoRouter.PUT("/:todoID/field/:fieldID", routes.todoController.SetTodoFieldValue)
+	todoRouter.DELETE("/:todoID/field/:fieldID", routes.todoController.DeleteTodoFieldValue)
+
+	stateRouter := router.Group("/:listID/state", middleware.TokenAuthMiddleware("lists"))
+	stateRouter.GET("/", routes.todoController.ReadStates)
+	stateRouter.POST("/", routes.todoController.CreateState)
+	stateRouter.PATCH("/:stateID", routes.todoController.UpdateState)
+	stateRouter.DELETE("/:stateID", routes.todoController.DeleteState)
+
+	fieldRouter := router.Group("/:listID/field", middleware.TokenAuthMiddleware("lists"))
+	fieldRouter.GET("/", routes.todoController.ReadFields)
+	fieldRouter.POST("/", routes.todoController.CreateField)
+	fieldRouter.PATCH("/:fieldID", routes.todoController.UpdateField)
+	fieldRouter.DELETE("/:fieldID", routes.todoController.DeleteField)
 
 	// TODO Implement create share
 	// TODO Implement delete share
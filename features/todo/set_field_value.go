@@ -0,0 +1,128 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Sets the value of a custom field on a todo. The value must match the type
+// of the field.
+func (controller *TodoController) SetTodoFieldValue(ctx *gin.Context) {
+	var payload *schemas.SetTodoFieldValue
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	}
+
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+	listID := ctx.Param("listID")
+	todoID := ctx.Param("todoID")
+	fieldID := ctx.Param("fieldID")
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	listIds, err := controller.db.GetListIdsAccessible(ctx, reqUser.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError(
+			"failed to get list accessible by user",
+			file,
+			line,
+			err,
+			ctx,
+		)
+		return
+	}
+	if !slices.Contains(listIds, listID) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			fmt.Sprintf("list: %v, todo: %v", listID, todoID),
+			reqUser.ID,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	todo, err := controller.db.GetTodoByIdWithListId(ctx, db.GetTodoByIdWithListIdParams{ID: todoID, ListID: listID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get todo", file, line, err, ctx)
+		return
+	}
+
+	field, err := controller.db.GetListField(ctx, db.GetListFieldParams{ID: fieldID, ListID: listID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get field", file, line, err, ctx)
+		return
+	}
+
+	value, ok := encodeFieldValue(field, payload.Value)
+	if !ok {
+		ctx.Error(gterrors.NewGtValueError(fmt.Sprint(payload.Value), "invalid "+field.FieldType+" value"))
+		return
+	}
+
+	args := &db.UpsertTodoFieldValueParams{
+		TodoID:  todo.ID,
+		FieldID: field.ID,
+		Value:   value,
+	}
+	fieldValue, err := controller.db.UpsertTodoFieldValue(ctx, *args)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to set field value", file, line, err, ctx)
+		return
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventUpdate,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		&fieldValue,
+		nil,
+		logging.ObjectEventSubField,
+	)
+	ctx.JSON(200, gin.H{
+		"status": "ok",
+		"field":  field.Name,
+		"value":  decodeFieldValue(field.FieldType, fieldValue.Value),
+	})
+}
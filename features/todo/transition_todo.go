@@ -0,0 +1,117 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Moves a todo to another state of its list. completed and completed_at
+// follow the target state.
+func (controller *TodoController) TransitionTodo(ctx *gin.Context) {
+	var payload *schemas.TransitionTodo
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	}
+
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+	listID := ctx.Param("listID")
+	todoID := ctx.Param("todoID")
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	listIds, err := controller.db.GetListIdsAccessible(ctx, reqUser.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError(
+			"failed to get list accessible by user",
+			file,
+			line,
+			err,
+			ctx,
+		)
+		return
+	}
+	if !slices.Contains(listIds, listID) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			fmt.Sprintf("list: %v, todo: %v", listID, todoID),
+			reqUser.ID,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	oldTodo, err := controller.db.GetTodoByIdWithListId(ctx, db.GetTodoByIdWithListIdParams{ID: todoID, ListID: listID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get todo", file, line, err, ctx)
+		return
+	}
+
+	// The state must belong to the same list as the todo
+	_, err = controller.db.GetListState(ctx, db.GetListStateParams{ID: payload.StateID, ListID: listID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.NewGtValueError(payload.StateID, "unknown state"))
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get state", file, line, err, ctx)
+		return
+	}
+
+	newTodo, err := controller.db.UpdateTodoState(ctx, db.UpdateTodoStateParams{ID: oldTodo.ID, StateID: payload.StateID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to transition todo", file, line, err, ctx)
+		return
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventUpdate,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		&newTodo,
+		&oldTodo,
+		logging.ObjectEventSubTodo,
+	)
+	ctx.JSON(200, gin.H{"status": "ok", "todo": newTodo})
+}
@@ -0,0 +1,151 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+	"go-todo/util/validate"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Updates a custom field. The type of a field can't be changed. Values of a
+// select field that are no longer among its options are dropped.
+func (controller *TodoController) UpdateField(ctx *gin.Context) {
+	var payload *schemas.UpdateListField
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	} else if payload.Name == nil && payload.Options == nil && payload.Position == nil {
+		ctx.JSON(200, gin.H{"status": "not-modified"})
+		return
+	}
+
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	listID := ctx.Param("listID")
+	fieldID := ctx.Param("fieldID")
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	list, err := controller.db.GetList(ctx, listID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get list", file, line, err, ctx)
+		return
+	}
+	if list.UserID != reqUser.ID && !mycontext.HasAdminRights(reqUser, ctx) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			fmt.Sprintf("list: %v, field: %v", listID, fieldID),
+			reqUser.ID,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	oldField, err := controller.db.GetListField(ctx, db.GetListFieldParams{ID: fieldID, ListID: list.ID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get field", file, line, err, ctx)
+		return
+	}
+
+	name := oldField.Name
+	options := oldField.Options
+	position := oldField.Position
+	if payload.Name != nil {
+		name = *payload.Name
+	}
+	if payload.Options != nil {
+		options = *payload.Options
+		if options == nil {
+			options = []string{}
+		}
+	}
+	if payload.Position != nil {
+		position = *payload.Position
+	}
+	if !validate.LengthTitle(name) {
+		ctx.Error(gterrors.NewGtValueError(name, "name too long"))
+		return
+	}
+	if detail := checkFieldOptions(oldField.FieldType, options); detail != "" {
+		ctx.Error(gterrors.NewGtValueError(fmt.Sprint(options), detail))
+		return
+	}
+
+	args := &db.UpdateListFieldParams{
+		ID:       oldField.ID,
+		Name:     name,
+		Options:  options,
+		Position: position,
+	}
+	newField, err := controller.db.UpdateListField(ctx, *args)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			ctx.Error(gterrors.ErrUniqueViolation).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to update field", file, line, err, ctx)
+		return
+	}
+
+	if newField.FieldType == FieldTypeSelect && payload.Options != nil {
+		cleanArgs := &db.DeleteTodoFieldValuesNotInOptionsParams{
+			FieldID: newField.ID,
+			Options: newField.Options,
+		}
+		if _, err := controller.db.DeleteTodoFieldValuesNotInOptions(ctx, *cleanArgs); err != nil {
+			_, file, line, _ := runtime.Caller(0)
+			mycontext.CtxAddGtInternalError("failed to drop stale field values", file, line, err, ctx)
+			return
+		}
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventUpdate,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		&newField,
+		&oldField,
+		logging.ObjectEventSubField,
+	)
+	ctx.JSON(200, gin.H{"status": "ok", "field": newField})
+}
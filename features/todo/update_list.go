@@ -58,7 +58,7 @@ func (controller *TodoController) UpdateList(ctx *gin.Context) {
 		return
 	}
 
-	if oldList.UserID != reqUser.ID && !reqUser.IsAdmin {
+	if oldList.UserID != reqUser.ID && !mycontext.HasAdminRights(reqUser, ctx) {
 		ctx.Error(gterrors.ErrForbidden).SetType(gterrors.GetGinErrorType())
 		return
 	}
@@ -97,6 +97,7 @@ func (controller *TodoController) UpdateList(ctx *gin.Context) {
 		ctx.ClientIP(),
 		logging.ObjectEventUpdate,
 		reqUser,
+		mycontext.GetTokenAct(ctx),
 		&newList,
 		&oldList,
 		logging.ObjectEventSubList,
@@ -0,0 +1,145 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/database"
+	"go-todo/util/mycontext"
+	"go-todo/util/validate"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Updates a workflow state. If is_done changes, todos in the state are
+// completed or reopened accordingly.
+func (controller *TodoController) UpdateState(ctx *gin.Context) {
+	var payload *schemas.UpdateListState
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	} else if payload.Name == nil && payload.IsDone == nil && payload.Position == nil {
+		ctx.JSON(200, gin.H{"status": "not-modified"})
+		return
+	}
+
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	listID := ctx.Param("listID")
+	stateID := ctx.Param("stateID")
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return
+	}
+
+	list, err := controller.db.GetList(ctx, listID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get list", file, line, err, ctx)
+		return
+	}
+	if list.UserID != reqUser.ID && !mycontext.HasAdminRights(reqUser, ctx) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			fmt.Sprintf("list: %v, state: %v", listID, stateID),
+			reqUser.ID,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	oldState, err := controller.db.GetListState(ctx, db.GetListStateParams{ID: stateID, ListID: list.ID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get state", file, line, err, ctx)
+		return
+	}
+
+	name := oldState.Name
+	isDone := oldState.IsDone
+	position := oldState.Position
+	if payload.Name != nil {
+		name = *payload.Name
+	}
+	if payload.IsDone != nil {
+		isDone = *payload.IsDone
+	}
+	if payload.Position != nil {
+		position = *payload.Position
+	}
+	if !validate.LengthTitle(name) {
+		ctx.Error(gterrors.NewGtValueError(name, "name too long"))
+		return
+	}
+
+	args := &db.UpdateListStateParams{
+		ID:       oldState.ID,
+		Name:     name,
+		Position: position,
+		IsDone:   isDone,
+	}
+	newState, err := controller.db.UpdateListState(ctx, *args)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			ctx.Error(gterrors.ErrUniqueViolation).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to update state", file, line, err, ctx)
+		return
+	}
+
+	if oldState.IsDone != newState.IsDone {
+		syncArgs := &db.SyncTodosCompletedByStateIdParams{
+			StateID:   pgtype.Text{String: newState.ID, Valid: true},
+			Completed: newState.IsDone,
+		}
+		if err := controller.db.SyncTodosCompletedByStateId(ctx, *syncArgs); err != nil {
+			_, file, line, _ := runtime.Caller(0)
+			mycontext.CtxAddGtInternalError("failed to sync todos with state", file, line, err, ctx)
+			return
+		}
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventUpdate,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		&newState,
+		&oldState,
+		logging.ObjectEventSubState,
+	)
+	ctx.JSON(200, gin.H{"status": "ok", "state": newState})
+}
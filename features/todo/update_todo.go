@@ -26,7 +26,8 @@ func (controller *TodoController) UpdateTodo(ctx *gin.Context) {
 	} else if payload.Title == nil &&
 		payload.Description == nil &&
 		payload.CompleteBefore == nil &&
-		payload.Completed == nil {
+		payload.Completed == nil &&
+		payload.StateID == nil {
 		ctx.JSON(200, gin.H{"status": "not-modified"})
 		return
 	}
@@ -111,11 +112,37 @@ func (controller *TodoController) UpdateTodo(ctx *gin.Context) {
 		completed = *payload.Completed
 	}
 
+	states, err := controller.db.GetListStatesByListId(ctx, listID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get states", file, line, err, ctx)
+		return
+	}
+	stateID := oldTodo.StateID
+	if payload.StateID != nil {
+		idx := slices.IndexFunc(states, func(s db.ListState) bool { return s.ID == *payload.StateID })
+		if idx == -1 {
+			ctx.Error(gterrors.NewGtValueError(*payload.StateID, "unknown state"))
+			return
+		}
+		stateID = pgtype.Text{String: states[idx].ID, Valid: true}
+	} else if payload.Completed != nil && len(states) != 0 {
+		// Toggling completed on a list with states moves the todo to the
+		// first state matching the requested value.
+		idx := slices.IndexFunc(states, func(s db.ListState) bool { return s.IsDone == completed })
+		if idx == -1 {
+			ctx.Error(gterrors.NewGtValueError(fmt.Sprint(completed), "no matching state in list"))
+			return
+		}
+		stateID = pgtype.Text{String: states[idx].ID, Valid: true}
+	}
+
 	updateArgs := &db.UpdateTodoParams{
 		ID:             todoID,
 		Title:          title,
 		Description:    pgtype.Text{String: description, Valid: true},
 		CompleteBefore: pgtype.Timestamp{Time: *completeBefore, Valid: completeBeforeIsValid},
+		StateID:        stateID,
 		Completed:      completed,
 	}
 	newTodo, err := controller.db.UpdateTodo(ctx, *updateArgs)
@@ -136,6 +163,7 @@ func (controller *TodoController) UpdateTodo(ctx *gin.Context) {
 		ctx.ClientIP(),
 		logging.ObjectEventUpdate,
 		reqUser,
+		mycontext.GetTokenAct(ctx),
 		&newTodo,
 		&oldTodo,
 		logging.ObjectEventSubTodo,
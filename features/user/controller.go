@@ -3,13 +3,61 @@ package user
 import (
 	"context"
 	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/util/database"
+	"go-todo/util/mailer"
+	"go-todo/util/mycontext"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type UserController struct {
-	db  *db.Queries
-	ctx context.Context
+	pool *pgxpool.Pool
+	db   *db.Queries
+	// Nil when mail is disabled.
+	mailer mailer.Mailer
+	ctx    context.Context
+}
+
+func NewController(pool *pgxpool.Pool, db *db.Queries, mailer mailer.Mailer, ctx context.Context) *UserController {
+	return &UserController{pool: pool, db: db, mailer: mailer, ctx: ctx}
 }
 
-func NewController(db *db.Queries, ctx context.Context) *UserController {
-	return &UserController{db: db, ctx: ctx}
+// Returns the requesting user if they are an admin. Errors are pushed to
+// gin.Context and false is returned otherwise. target is logged if the
+// user isn't an admin.
+func (controller *UserController) getAdmin(target string, ctx *gin.Context) (*db.User, bool) {
+	requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return nil, false
+	}
+
+	reqUser, err := database.GetUserById(controller.db, requesterId, ctx)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			requesterUsername,
+			ctx.ClientIP(),
+		)
+		return nil, false
+	}
+	if !mycontext.HasAdminRights(reqUser, ctx) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreMedium,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			target,
+			reqUser.ID,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gin.ErrorTypePublic)
+		return nil, false
+	}
+	return reqUser, true
 }
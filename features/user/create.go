@@ -2,45 +2,74 @@ package user
 
 import (
 	"errors"
+	"fmt"
 	db "go-todo/db/sqlc"
 	"go-todo/gterrors"
 	"go-todo/logging"
 	"go-todo/schemas"
+	"go-todo/util/database"
 	"go-todo/util/mycontext"
 	"go-todo/util/passwd"
+	"go-todo/util/registration"
+	"go-todo/util/securetoken"
 	"go-todo/util/validate"
 	"net/http"
 	"runtime"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+var errInviteCodeInvalid = errors.New("invite code is unknown, used up or expired")
+
+// Creates a user. Who can do it depends on the registration mode, admins
+// always can and are the only ones who can create other admins.
 func (controller *UserController) CreateUser(ctx *gin.Context) {
 	var payload *schemas.CreateUser
 	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
 		return
 	}
 
-	makeAdmin := false
-	users, err := controller.db.GetAllUsers(ctx)
-	if err != nil {
-		_, file, line, _ := runtime.Caller(0)
-		mycontext.CtxAddGtInternalError("failed to get users from db", file, line, err, ctx)
-		return
+	// Admins can create users whatever the registration mode is.
+	var reqUser *db.User
+	if requesterId, requesterUsername, _, err := mycontext.GetTokenVariables(ctx); err == nil {
+		reqUser, err = database.GetUserById(controller.db, requesterId, ctx)
+		if err != nil {
+			logging.LogSecurityEvent(
+				logging.SecurityScoreLow,
+				logging.SecurityEventJwtUserUnknown,
+				ctx.FullPath(),
+				requesterUsername,
+				ctx.ClientIP(),
+			)
+			return
+		}
 	}
-	if len(users) == 0 {
-		makeAdmin = true
+	byAdmin := reqUser != nil && mycontext.HasAdminRights(reqUser, ctx)
+
+	needsInvite := false
+	if !byAdmin {
+		switch registration.GetMode() {
+		case registration.ModeClosed:
+			ctx.Error(
+				fmt.Errorf("%w: registration is closed", gterrors.ErrForbidden),
+			).SetType(gterrors.GetGinErrorType())
+			return
+		case registration.ModeInvite:
+			if payload.InviteCode == "" {
+				ctx.Error(gterrors.NewGtValueError("invite_code", "an invite code is required")).SetType(gin.ErrorTypePublic)
+				return
+			}
+			needsInvite = true
+		}
 	}
 
-	isPasswdValid, err := validate.Password(payload.Password)
-	if err != nil {
-		_, file, line, _ := runtime.Caller(0)
-		mycontext.CtxAddGtInternalError("failed to validate new password", file, line, err, ctx)
-		return
-	} else if !isPasswdValid {
-		ctx.Error(gterrors.ErrPasswordUnsatisfied).SetType(gin.ErrorTypePublic)
+	if err := validate.Password(payload.Password, payload.Username); err != nil {
+		ctx.Error(err).SetType(gin.ErrorTypePublic)
 		return
 	}
 
@@ -67,10 +96,42 @@ func (controller *UserController) CreateUser(ctx *gin.Context) {
 		ID:           userUUID.String(),
 		Username:     payload.Username,
 		PasswordHash: passwdHash,
-		IsAdmin:      makeAdmin,
+		IsAdmin:      byAdmin && payload.IsAdmin,
 	}
 
-	user, err := controller.db.CreateUser(ctx, *args)
+	var user db.CreateUserRow
+	if needsInvite {
+		// The use is given back if the user can't be created.
+		err = database.WithTx(ctx, controller.pool, controller.db, func(q *db.Queries) error {
+			useArgs := &db.UseInviteCodeParams{
+				CodeHash:  securetoken.Hash(payload.InviteCode),
+				ExpiresAt: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+			}
+			if _, err := q.UseInviteCode(ctx, *useArgs); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return errInviteCodeInvalid
+				}
+				return err
+			}
+			user, err = q.CreateUser(ctx, *args)
+			return err
+		})
+	} else {
+		user, err = controller.db.CreateUser(ctx, *args)
+	}
+	if errors.Is(err, errInviteCodeInvalid) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreMedium,
+			logging.SecurityEventInviteCodeInvalid,
+			ctx.FullPath(),
+			payload.Username,
+			ctx.ClientIP(),
+		)
+		ctx.Error(
+			gterrors.NewGtAuthError(gterrors.GtAuthErrorReasonTokenInvalid, err),
+		).SetType(gterrors.GetGinErrorType())
+		return
+	}
 	if err != nil {
 		var pgErr *pgconn.PgError
 		errMessage := "failed to create user"
@@ -93,7 +154,8 @@ func (controller *UserController) CreateUser(ctx *gin.Context) {
 		ctx.FullPath(),
 		ctx.ClientIP(),
 		logging.ObjectEventCreate,
-		nil,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
 		&user,
 		nil,
 		logging.ObjectEventSubUser,
@@ -39,7 +39,7 @@ func (controller *UserController) DeleteUser(ctx *gin.Context) {
 	}
 
 	userIDToDelete := ctx.Param("id")
-	if userIDToDelete != reqUser.ID && !reqUser.IsAdmin {
+	if userIDToDelete != reqUser.ID && !mycontext.HasAdminRights(&reqUser, ctx) {
 		logging.LogSecurityEvent(
 			logging.SecurityScoreMedium,
 			logging.SecurityEventForbiddenAction,
@@ -67,6 +67,7 @@ func (controller *UserController) DeleteUser(ctx *gin.Context) {
 		ctx.ClientIP(),
 		logging.ObjectEventDelete,
 		&reqUser,
+		mycontext.GetTokenAct(ctx),
 		"deleted",
 		userIDToDelete,
 		logging.ObjectEventSubUser,
@@ -0,0 +1,182 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/config"
+	"go-todo/util/mailer"
+	"go-todo/util/mycontext"
+	"go-todo/util/securetoken"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Mails a confirmation token to the new address of the user. The address is
+// set by ConfirmEmail once the token is used, and only the newest token
+// works.
+func (controller *UserController) requestEmailVerification(user db.User, email string, ctx *gin.Context) bool {
+	cfg, err := config.Get()
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get config", file, line, err, ctx)
+		return false
+	}
+
+	token, tokenHash, err := securetoken.Generate()
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to generate verification token", file, line, err, ctx)
+		return false
+	}
+
+	if err := controller.db.DeleteEmailVerificationTokensByUserId(ctx, user.ID); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to delete old verification tokens", file, line, err, ctx)
+		return false
+	}
+	lifeSpan := time.Duration(cfg.EmailVerificationLifeSpan) * time.Minute
+	args := &db.CreateEmailVerificationTokenParams{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		Email:     email,
+		TokenHash: tokenHash,
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().Add(lifeSpan).UTC(), Valid: true},
+	}
+	if err := controller.db.CreateEmailVerificationToken(ctx, *args); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to save verification token", file, line, err, ctx)
+		return false
+	}
+
+	msg := emailVerificationMessage(cfg, user, email, token)
+	go func() {
+		if err := controller.mailer.Send(context.Background(), msg); err != nil {
+			_, file, line, _ := runtime.Caller(0)
+			logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Failed to send email verification mail.")
+		}
+	}()
+	return true
+}
+
+func emailVerificationMessage(cfg *config.Config, user db.User, email string, token string) mailer.Message {
+	instructions := fmt.Sprintf("Use this token to confirm the address: %v", token)
+	if cfg.EmailVerificationUrl != "" {
+		separator := "?"
+		if strings.Contains(cfg.EmailVerificationUrl, "?") {
+			separator = "&"
+		}
+		instructions = fmt.Sprintf(
+			"Use this link to confirm the address: %v%vtoken=%v",
+			cfg.EmailVerificationUrl,
+			separator,
+			token,
+		)
+	}
+	return mailer.Message{
+		To:      email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf(
+			"This address was added to the account %v.\n\n%v\n\n"+
+				"It expires in %d minutes. If you didn't add the address, you "+
+				"can ignore this mail.",
+			user.Username,
+			instructions,
+			cfg.EmailVerificationLifeSpan,
+		),
+	}
+}
+
+// Sets the address of a token from requestEmailVerification as the user's
+// verified email.
+func (controller *UserController) ConfirmEmail(ctx *gin.Context) {
+	var payload *schemas.ConfirmEmail
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	}
+
+	tokenArgs := &db.GetValidEmailVerificationTokenParams{
+		TokenHash: securetoken.Hash(payload.Token),
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	}
+	verification, err := controller.db.GetValidEmailVerificationToken(ctx, *tokenArgs)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logging.LogSecurityEvent(
+				logging.SecurityScoreMedium,
+				logging.SecurityEventEmailVerificationTokenInvalid,
+				ctx.FullPath(),
+				"",
+				ctx.ClientIP(),
+			)
+			ctx.Error(
+				gterrors.NewGtAuthError(
+					gterrors.GtAuthErrorReasonTokenInvalid,
+					errors.New("verification token is unknown, used or expired"),
+				),
+			).SetType(gterrors.GetGinErrorType())
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get verification token from db", file, line, err, ctx)
+		return
+	}
+
+	// Should only fail if something is wrong in the server
+	user, err := controller.db.GetUserById(ctx, verification.UserID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get user from db", file, line, err, ctx)
+		return
+	}
+
+	args := &db.VerifyUserEmailParams{
+		ID:              user.ID,
+		Email:           pgtype.Text{String: verification.Email, Valid: true},
+		EmailVerifiedAt: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	}
+	if err := controller.db.VerifyUserEmail(ctx, *args); err != nil {
+		// Someone else confirmed the address first.
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			ctx.Error(gterrors.ErrUniqueViolation).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to set email", file, line, err, ctx)
+		return
+	}
+	if err := controller.db.DeleteEmailVerificationTokensByUserId(ctx, user.ID); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to delete verification tokens", file, line, err, ctx)
+		return
+	}
+
+	verifiedUser := user
+	verifiedUser.Email = args.Email
+	verifiedUser.EmailVerifiedAt = args.EmailVerifiedAt
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventUpdate,
+		&user,
+		nil,
+		&verifiedUser,
+		&user,
+		logging.ObjectEventSubUser,
+	)
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
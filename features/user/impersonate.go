@@ -0,0 +1,108 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/jwt"
+	"go-todo/util/mycontext"
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Issues an access token for acting as the user. Admin only. The token names
+// the admin in its act claim, can't be refreshed and can't be used to get
+// another impersonation token.
+func (controller *UserController) ImpersonateUser(ctx *gin.Context) {
+	tokenUserId, tokenUserName, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	var payload *schemas.ImpersonateUser
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	}
+
+	reqUser, err := controller.db.GetUserById(ctx, tokenUserId)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			tokenUserName,
+			ctx.ClientIP(),
+		)
+		ctx.Error(
+			gterrors.NewGtAuthError(
+				gterrors.GtAuthErrorReasonJwtUserNotFound,
+				fmt.Errorf("could not get user from db: %w", err),
+			),
+		).SetType(gterrors.GetGinErrorType())
+		return
+	}
+
+	userIDToImpersonate := ctx.Param("id")
+	if !mycontext.HasAdminRights(&reqUser, ctx) || userIDToImpersonate == reqUser.ID {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreMedium,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			userIDToImpersonate,
+			reqUser.Username,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gterrors.GetGinErrorType())
+		return
+	}
+
+	user, err := controller.db.GetUserById(ctx, userIDToImpersonate)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get user from db", file, line, err, ctx)
+		return
+	}
+
+	if user.IsAdmin && !payload.AllowAdmin {
+		ctx.Error(
+			fmt.Errorf("%w: impersonating an admin requires allow_admin", gterrors.ErrForbidden),
+		).SetType(gterrors.GetGinErrorType())
+		return
+	}
+
+	accessToken, accessClaims, err := jwt.GenerateImpersonationJwt(
+		user.Username,
+		user.ID,
+		user.IsAdmin,
+		jwt.ActClaim{Subject: reqUser.ID, Username: reqUser.Username},
+	)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to generate impersonation jwt", file, line, err, ctx)
+		return
+	}
+
+	logging.LogImpersonationEvent(
+		ctx.FullPath(),
+		reqUser.Username,
+		user.Username,
+		accessClaims.ID,
+		ctx.ClientIP(),
+	)
+	logging.LogTokenEvent(true, ctx.FullPath(), logging.TokenEventtypeCreate, ctx.RemoteIP(), accessClaims)
+	ctx.JSON(http.StatusOK, gin.H{
+		"status":       "ok",
+		"access_token": accessToken,
+		"expires_at":   accessClaims.ExpiresAt.Time,
+	})
+}
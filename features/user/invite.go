@@ -0,0 +1,155 @@
+package user
+
+import (
+	"net/http"
+	"runtime"
+	"time"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/config"
+	"go-todo/util/mycontext"
+	"go-todo/util/securetoken"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func toResponseInviteCode(invite db.InviteCode) schemas.ResponseInviteCode {
+	return schemas.ResponseInviteCode{
+		ID:        invite.ID,
+		CreatedBy: invite.CreatedBy,
+		MaxUses:   invite.MaxUses,
+		Uses:      invite.Uses,
+		ExpiresAt: invite.ExpiresAt.Time,
+		CreatedAt: invite.CreatedAt.Time,
+	}
+}
+
+// Creates an invite code for CreateUser. The code is only in this response,
+// the database has its hash.
+func (controller *UserController) CreateInviteCode(ctx *gin.Context) {
+	reqUser, ok := controller.getAdmin("invite codes", ctx)
+	if !ok {
+		return
+	}
+
+	var payload *schemas.CreateInviteCode
+	if ok := mycontext.ShouldBindBodyWithJSON(&payload, ctx); !ok {
+		return
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get config", file, line, err, ctx)
+		return
+	}
+
+	maxUses := payload.MaxUses
+	if maxUses == 0 {
+		maxUses = 1
+	} else if maxUses < 0 {
+		ctx.Error(gterrors.NewGtValueError("max_uses", "must be at least 1")).SetType(gin.ErrorTypePublic)
+		return
+	}
+	expiresAt := time.Now().Add(time.Duration(cfg.InviteCodeLifeSpan) * time.Minute)
+	if payload.ExpiresAt != nil {
+		if !payload.ExpiresAt.After(time.Now()) {
+			ctx.Error(gterrors.NewGtValueError(payload.ExpiresAt.String(), "expiry must be in the future")).SetType(gin.ErrorTypePublic)
+			return
+		}
+		expiresAt = *payload.ExpiresAt
+	}
+
+	code, codeHash, err := securetoken.Generate()
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to generate invite code", file, line, err, ctx)
+		return
+	}
+
+	invite, err := controller.db.CreateInviteCode(ctx, db.CreateInviteCodeParams{
+		ID:        uuid.New().String(),
+		CodeHash:  codeHash,
+		CreatedBy: reqUser.ID,
+		MaxUses:   maxUses,
+		ExpiresAt: pgtype.Timestamp{Time: expiresAt.UTC(), Valid: true},
+	})
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to save invite code", file, line, err, ctx)
+		return
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventCreate,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		&invite,
+		nil,
+		logging.ObjectEventSubInviteCode,
+	)
+	ctx.JSON(http.StatusCreated, gin.H{
+		"status":      "created",
+		"code":        code,
+		"invite_code": toResponseInviteCode(invite),
+	})
+}
+
+// Lists the invite codes, including used up and expired ones, without the
+// codes themselves.
+func (controller *UserController) ReadInviteCodes(ctx *gin.Context) {
+	if _, ok := controller.getAdmin("invite codes", ctx); !ok {
+		return
+	}
+
+	invites, err := controller.db.GetInviteCodes(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get invite codes", file, line, err, ctx)
+		return
+	}
+
+	response := make([]schemas.ResponseInviteCode, 0, len(invites))
+	for _, invite := range invites {
+		response = append(response, toResponseInviteCode(invite))
+	}
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok", "invite_codes": response})
+}
+
+// Revokes an invite code. Users who registered with it are kept.
+func (controller *UserController) DeleteInviteCode(ctx *gin.Context) {
+	reqUser, ok := controller.getAdmin("invite codes", ctx)
+	if !ok {
+		return
+	}
+
+	inviteID := ctx.Param("inviteID")
+	rows, err := controller.db.DeleteInviteCode(ctx, inviteID)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to delete invite code", file, line, err, ctx)
+		return
+	} else if rows == 0 {
+		ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+		return
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventDelete,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		inviteID,
+		nil,
+		logging.ObjectEventSubInviteCode,
+	)
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
@@ -0,0 +1,135 @@
+package user
+
+import (
+	"runtime"
+	"strconv"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/schemas"
+	"go-todo/util/mycontext"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	listUsersDefaultLimit = 50
+	listUsersMaxLimit     = 100
+)
+
+// Lists users for admins. Supports the query parameters username (prefix),
+// is_admin, sort (username or created_at), order (asc or desc), limit and
+// offset.
+func (controller *UserController) ListUsers(ctx *gin.Context) {
+	reqUser, ok := controller.getAdmin("users", ctx)
+	if !ok {
+		return
+	}
+
+	args, ok := listUsersParams(ctx)
+	if !ok {
+		return
+	}
+
+	total, err := controller.db.CountUsers(ctx, db.CountUsersParams{
+		UsernamePrefix: args.UsernamePrefix,
+		IsAdmin:        args.IsAdmin,
+	})
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to count users", file, line, err, ctx)
+		return
+	}
+	rows, err := controller.db.ListUsers(ctx, *args)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get users from db", file, line, err, ctx)
+		return
+	}
+
+	users := make([]schemas.ListedUser, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, schemas.ListedUser{
+			ResponseUser: schemas.ResponseUser{
+				Id:            row.ID,
+				Username:      row.Username,
+				IsAdmin:       row.IsAdmin,
+				CreatedAt:     row.CreatedAt.Time,
+				Email:         row.Email.String,
+				EmailVerified: row.Email.Valid && row.EmailVerifiedAt.Valid,
+				DisplayName:   row.DisplayName,
+				Timezone:      row.Timezone,
+				Locale:        row.Locale,
+			},
+			ListCount:    row.ListCount,
+			SessionCount: row.SessionCount,
+		})
+	}
+
+	logging.LogObjectEvent(
+		ctx.FullPath(),
+		ctx.ClientIP(),
+		logging.ObjectEventRead,
+		reqUser,
+		mycontext.GetTokenAct(ctx),
+		rows,
+		nil,
+		logging.ObjectEventSubUser,
+	)
+	ctx.JSON(200, gin.H{
+		"status": "ok",
+		"users":  users,
+		"total":  total,
+		"limit":  args.RowLimit,
+		"offset": args.RowOffset,
+	})
+}
+
+// Reads the filters, sorting and paging of ListUsers from the query.
+func listUsersParams(ctx *gin.Context) (*db.ListUsersParams, bool) {
+	args := &db.ListUsersParams{
+		UsernamePrefix: ctx.Query("username"),
+		SortBy:         ctx.DefaultQuery("sort", "created_at"),
+		RowLimit:       listUsersDefaultLimit,
+	}
+
+	if isAdmin := ctx.Query("is_admin"); isAdmin != "" {
+		value, err := strconv.ParseBool(isAdmin)
+		if err != nil {
+			ctx.Error(gterrors.NewGtValueError(isAdmin, "is_admin must be true or false"))
+			return nil, false
+		}
+		args.IsAdmin = pgtype.Bool{Bool: value, Valid: true}
+	}
+
+	if args.SortBy != "username" && args.SortBy != "created_at" {
+		ctx.Error(gterrors.NewGtValueError(args.SortBy, "sort must be username or created_at"))
+		return nil, false
+	}
+	order := ctx.DefaultQuery("order", "asc")
+	if order != "asc" && order != "desc" {
+		ctx.Error(gterrors.NewGtValueError(order, "order must be asc or desc"))
+		return nil, false
+	}
+	args.SortDesc = order == "desc"
+
+	if limit := ctx.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > listUsersMaxLimit {
+			ctx.Error(gterrors.NewGtValueError(limit, "limit must be between 1 and "+strconv.Itoa(listUsersMaxLimit)))
+			return nil, false
+		}
+		args.RowLimit = int32(value)
+	}
+	if offset := ctx.Query("offset"); offset != "" {
+		value, err := strconv.ParseInt(offset, 10, 32)
+		if err != nil || value < 0 {
+			ctx.Error(gterrors.NewGtValueError(offset, "offset must be a non-negative number"))
+			return nil, false
+		}
+		args.RowOffset = int32(value)
+	}
+	return args, true
+}
@@ -0,0 +1,88 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/util/mycontext"
+	"go-todo/util/revocation"
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Ends every session of the user and revokes the access tokens issued to
+// them. Admins can log out any user.
+func (controller *UserController) LogoutUser(ctx *gin.Context) {
+	tokenUserId, tokenUserName, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	reqUser, err := controller.db.GetUserById(ctx, tokenUserId)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			tokenUserName,
+			ctx.ClientIP(),
+		)
+		ctx.Error(
+			gterrors.NewGtAuthError(
+				gterrors.GtAuthErrorReasonJwtUserNotFound,
+				fmt.Errorf("could not get user from db: %w", err),
+			),
+		).SetType(gterrors.GetGinErrorType())
+		return
+	}
+
+	userIDToLogout := ctx.Param("id")
+	if userIDToLogout != reqUser.ID && !mycontext.HasAdminRights(&reqUser, ctx) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreMedium,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			userIDToLogout,
+			reqUser.Username,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gterrors.GetGinErrorType())
+		return
+	}
+
+	user, err := controller.db.GetUserById(ctx, userIDToLogout)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get user from db", file, line, err, ctx)
+		return
+	}
+
+	if err := controller.db.DeleteJwtTokensByUserId(ctx, user.ID); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to delete refresh jwts", file, line, err, ctx)
+		return
+	}
+	if err := revocation.RevokeUser(ctx, user.ID); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to revoke access tokens", file, line, err, ctx)
+		return
+	}
+
+	logging.LogSessionEvent(
+		true,
+		ctx.FullPath(),
+		user.Username,
+		logging.SessionEventTypeLogout,
+		ctx.ClientIP(),
+	)
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
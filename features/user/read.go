@@ -37,7 +37,7 @@ func (controller *UserController) ReadUser(ctx *gin.Context) {
 		return
 	}
 
-	if reqUser.ID != userIDToGet && !reqUser.IsAdmin {
+	if reqUser.ID != userIDToGet && !mycontext.HasAdminRights(reqUser, ctx) {
 		logging.LogSecurityEvent(
 			logging.SecurityScoreMedium,
 			logging.SecurityEventForbiddenAction,
@@ -69,6 +69,12 @@ func (controller *UserController) ReadUser(ctx *gin.Context) {
 		Username:  user.Username,
 		IsAdmin:   user.IsAdmin,
 		CreatedAt: user.CreatedAt.Time,
+		Email:     user.Email.String,
+		// Verifying an address that was later removed doesn't count.
+		EmailVerified: user.Email.Valid && user.EmailVerifiedAt.Valid,
+		DisplayName:   user.DisplayName,
+		Timezone:      user.Timezone,
+		Locale:        user.Locale,
 	}
 
 	logging.LogObjectEvent(
@@ -76,6 +82,7 @@ func (controller *UserController) ReadUser(ctx *gin.Context) {
 		ctx.ClientIP(),
 		logging.ObjectEventRead,
 		reqUser,
+		mycontext.GetTokenAct(ctx),
 		&user,
 		nil,
 		logging.ObjectEventSubUser,
@@ -16,8 +16,16 @@ func NewRoutes(userController *UserController) *UserRoutes {
 
 func (routes *UserRoutes) Register(rg *gin.RouterGroup) {
 	router := rg.Group("/user")
+	router.GET("/", middleware.JwtAuthMiddleware(), routes.userController.ListUsers)
 	router.GET("/:userID", middleware.JwtAuthMiddleware(), routes.userController.ReadUser)
-	router.POST("/", routes.userController.CreateUser)
-	router.PATCH("/:id", middleware.JwtAuthMiddleware(), routes.userController.UpdateUser)
-	router.DELETE("/:id", middleware.JwtAuthMiddleware(), routes.userController.DeleteUser)
+	router.POST("/", middleware.OptionalJwtAuthMiddleware(), routes.userController.CreateUser)
+	router.POST("/email/confirm", routes.userController.ConfirmEmail)
+	router.GET("/invite", middleware.JwtAuthMiddleware(), routes.userController.ReadInviteCodes)
+	router.POST("/invite", middleware.JwtAuthMiddleware(), routes.userController.CreateInviteCode)
+	router.DELETE("/invite/:inviteID", middleware.JwtAuthMiddleware(), routes.userController.DeleteInviteCode)
+	router.PATCH("/:id", middleware.JwtAuthMiddleware(), middleware.DenyImpersonationMiddleware(), routes.userController.UpdateUser)
+	router.DELETE("/:id", middleware.JwtAuthMiddleware(), middleware.DenyImpersonationMiddleware(), routes.userController.DeleteUser)
+	router.POST("/:id/logout", middleware.JwtAuthMiddleware(), middleware.DenyImpersonationMiddleware(), routes.userController.LogoutUser)
+	router.POST("/:id/unlock", middleware.JwtAuthMiddleware(), middleware.DenyImpersonationMiddleware(), routes.userController.UnlockUser)
+	router.POST("/:id/impersonate", middleware.JwtAuthMiddleware(), middleware.DenyImpersonationMiddleware(), routes.userController.ImpersonateUser)
 }
@@ -0,0 +1,81 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/util/loginguard"
+	"go-todo/util/mycontext"
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Clears the failed logins and any lockout of the user. Admin only.
+func (controller *UserController) UnlockUser(ctx *gin.Context) {
+	tokenUserId, tokenUserName, _, err := mycontext.GetTokenVariables(ctx)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get claims from jwt", file, line, err, ctx)
+		return
+	}
+
+	reqUser, err := controller.db.GetUserById(ctx, tokenUserId)
+	if err != nil {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreLow,
+			logging.SecurityEventJwtUserUnknown,
+			ctx.FullPath(),
+			tokenUserName,
+			ctx.ClientIP(),
+		)
+		ctx.Error(
+			gterrors.NewGtAuthError(
+				gterrors.GtAuthErrorReasonJwtUserNotFound,
+				fmt.Errorf("could not get user from db: %w", err),
+			),
+		).SetType(gterrors.GetGinErrorType())
+		return
+	}
+
+	userIDToUnlock := ctx.Param("id")
+	if !mycontext.HasAdminRights(&reqUser, ctx) {
+		logging.LogSecurityEvent(
+			logging.SecurityScoreMedium,
+			logging.SecurityEventForbiddenAction,
+			ctx.FullPath(),
+			userIDToUnlock,
+			reqUser.Username,
+		)
+		ctx.Error(gterrors.ErrForbidden).SetType(gterrors.GetGinErrorType())
+		return
+	}
+
+	user, err := controller.db.GetUserById(ctx, userIDToUnlock)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.Error(gterrors.ErrNotFound).SetType(gin.ErrorTypePublic)
+			return
+		}
+		_, file, line, _ := runtime.Caller(0)
+		mycontext.CtxAddGtInternalError("failed to get user from db", file, line, err, ctx)
+		return
+	}
+
+	wasLocked := loginguard.Unlock(user.Username)
+
+	logging.LogSessionEvent(
+		true,
+		ctx.FullPath(),
+		user.Username,
+		logging.SessionEventTypeUnlock,
+		ctx.ClientIP(),
+	)
+	ctx.JSON(http.StatusOK, gin.H{
+		"status":     "ok",
+		"was_locked": wasLocked,
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"runtime"
+	"strings"
 
 	db "go-todo/db/sqlc"
 	"go-todo/gterrors"
@@ -16,6 +17,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func (controller *UserController) UpdateUser(ctx *gin.Context) {
@@ -39,7 +41,7 @@ func (controller *UserController) UpdateUser(ctx *gin.Context) {
 
 	userIDToUpdate := ctx.Param("id")
 
-	if userIDToUpdate != reqUser.ID && !reqUser.IsAdmin {
+	if userIDToUpdate != reqUser.ID && !mycontext.HasAdminRights(&reqUser, ctx) {
 		logging.LogSecurityEvent(
 			logging.SecurityScoreHigh,
 			logging.SecurityEventForbiddenAction,
@@ -64,7 +66,31 @@ func (controller *UserController) UpdateUser(ctx *gin.Context) {
 		return
 	}
 
-	if !reqUser.IsAdmin && *payload.IsAdmin {
+	if payload.Email != nil && *payload.Email != "" && !validate.Email(*payload.Email) {
+		ctx.Error(gterrors.NewGtValueError(*payload.Email, "invalid email address")).SetType(gin.ErrorTypePublic)
+		return
+	}
+	if payload.DisplayName != nil {
+		*payload.DisplayName = strings.TrimSpace(*payload.DisplayName)
+		if !validate.DisplayName(*payload.DisplayName) {
+			ctx.Error(gterrors.NewGtValueError(*payload.DisplayName, "invalid display name")).SetType(gin.ErrorTypePublic)
+			return
+		}
+	}
+	if payload.Timezone != nil && !validate.Timezone(*payload.Timezone) {
+		ctx.Error(gterrors.NewGtValueError(*payload.Timezone, "unknown time zone")).SetType(gin.ErrorTypePublic)
+		return
+	}
+	if payload.Locale != nil {
+		locale, ok := validate.Locale(*payload.Locale)
+		if !ok {
+			ctx.Error(gterrors.NewGtValueError(*payload.Locale, "invalid locale")).SetType(gin.ErrorTypePublic)
+			return
+		}
+		*payload.Locale = locale
+	}
+
+	if !mycontext.HasAdminRights(&reqUser, ctx) && *payload.IsAdmin {
 		logging.LogSecurityEvent(
 			logging.SecurityScoreHigh,
 			logging.SecurityEventForbiddenAction,
@@ -92,12 +118,59 @@ func (controller *UserController) UpdateUser(ctx *gin.Context) {
 	} else {
 		oldUser = &reqUser
 	}
-	if oldUser.Username == payload.Username && oldUser.IsAdmin == *payload.IsAdmin {
+	// A new address is only set once it's confirmed, removing one works
+	// right away.
+	email := oldUser.Email
+	pendingEmail := ""
+	if payload.Email != nil {
+		if *payload.Email == "" {
+			email = pgtype.Text{}
+		} else if !oldUser.EmailVerifiedAt.Valid || !strings.EqualFold(*payload.Email, oldUser.Email.String) {
+			pendingEmail = *payload.Email
+		}
+	}
+	if pendingEmail != "" && controller.mailer == nil {
+		ctx.Error(
+			gterrors.NewGtValueError(pendingEmail, "addresses can't be confirmed while mail is disabled"),
+		).SetType(gin.ErrorTypePublic)
+		return
+	}
+	if pendingEmail != "" {
+		owner, err := controller.db.GetUserByEmail(ctx, pendingEmail)
+		if err == nil && owner.ID != oldUser.ID {
+			ctx.Error(gterrors.ErrUniqueViolation).SetType(gin.ErrorTypePublic)
+			return
+		} else if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			_, file, line, _ := runtime.Caller(0)
+			mycontext.CtxAddGtInternalError("failed to get user by email", file, line, err, ctx)
+			return
+		}
+	}
+	displayName := oldUser.DisplayName
+	if payload.DisplayName != nil {
+		displayName = *payload.DisplayName
+	}
+	timezone := oldUser.Timezone
+	if payload.Timezone != nil {
+		timezone = *payload.Timezone
+	}
+	locale := oldUser.Locale
+	if payload.Locale != nil {
+		locale = *payload.Locale
+	}
+	if oldUser.Username == payload.Username &&
+		oldUser.IsAdmin == *payload.IsAdmin &&
+		oldUser.Email == email &&
+		oldUser.DisplayName == displayName &&
+		oldUser.Timezone == timezone &&
+		oldUser.Locale == locale &&
+		pendingEmail == "" {
 		logging.LogObjectEvent(
 			ctx.FullPath(),
 			ctx.ClientIP(),
 			logging.ObjectEventUpdate,
 			&reqUser,
+			mycontext.GetTokenAct(ctx),
 			&oldUser,
 			&oldUser,
 			logging.ObjectEventSubUser,
@@ -107,9 +180,13 @@ func (controller *UserController) UpdateUser(ctx *gin.Context) {
 	}
 
 	args := &db.UpdateUserParams{
-		ID:       userIDToUpdate,
-		Username: payload.Username,
-		IsAdmin:  *payload.IsAdmin,
+		ID:          userIDToUpdate,
+		Username:    payload.Username,
+		IsAdmin:     *payload.IsAdmin,
+		Email:       email,
+		DisplayName: displayName,
+		Timezone:    timezone,
+		Locale:      locale,
 	}
 
 	updatedUser, err := controller.db.UpdateUser(ctx, *args)
@@ -158,8 +235,15 @@ func (controller *UserController) UpdateUser(ctx *gin.Context) {
 		}
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"status": "ok",
 		"user":   updatedUser,
-	})
+	}
+	if pendingEmail != "" {
+		if ok := controller.requestEmailVerification(*oldUser, pendingEmail, ctx); !ok {
+			return
+		}
+		response["pending_email"] = pendingEmail
+	}
+	ctx.JSON(http.StatusOK, response)
 }
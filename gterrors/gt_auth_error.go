@@ -18,6 +18,7 @@ const (
 	GtAuthErrorReasonJwtUserNotFound
 	GtAuthErrorReasonTokenReuse
 	GtAuthErrorReasonUsernameInvalid
+	GtAuthErrorReasonTokenRevoked
 )
 
 func (t GtAuthErrorReason) String() string {
@@ -38,6 +39,8 @@ func (t GtAuthErrorReason) String() string {
 		return "jwt-token-reuse"
 	case GtAuthErrorReasonUsernameInvalid:
 		return "username-invalid"
+	case GtAuthErrorReasonTokenRevoked:
+		return "token-revoked"
 	}
 	return "unknown"
 }
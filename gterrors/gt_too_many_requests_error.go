@@ -0,0 +1,31 @@
+package gterrors
+
+import (
+	"fmt"
+	"time"
+)
+
+type GtTooManyRequestsError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *GtTooManyRequestsError) Error() string {
+	return fmt.Sprintf("too many requests, retry after %d seconds: %v", RetryAfterSeconds(e.RetryAfter), e.Err)
+}
+
+func (e *GtTooManyRequestsError) Unwrap() error {
+	return e.Err
+}
+
+func NewGtTooManyRequestsError(retryAfter time.Duration, err error) *GtTooManyRequestsError {
+	return &GtTooManyRequestsError{
+		RetryAfter: retryAfter,
+		Err:        err,
+	}
+}
+
+// Rounds the duration up to whole seconds for the Retry-After header.
+func RetryAfterSeconds(d time.Duration) int {
+	return max(int((d+time.Second-1)/time.Second), 1)
+}
@@ -13,6 +13,7 @@ var ErrNotFound = errors.New("resource not found")
 var ErrPasswordUnsatisfied = errors.New("password criteria not met")
 var ErrPasswordSame = errors.New("password cannot be the old one")
 var ErrShouldNotHappen = errors.New("this should not happen")
+var ErrTimerRunning = errors.New("timer already running")
 var ErrUniqueViolation = errors.New("already exists")
 var ErrUsernameUnsatisfied = errors.New("username criteria not met")
 
@@ -0,0 +1,22 @@
+package logging
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Logs the result of a maintenance task, such as deleting expired rows.
+func LogMaintenanceEvent(task string, duration time.Duration, counts ...slog.Attr) {
+	args := []any{}
+	for _, count := range counts {
+		args = append(args, count)
+	}
+	log(
+		slog.LevelInfo,
+		"Maintenance task finished",
+		"maintenance",
+		slog.String("task", task),
+		slog.String("duration", duration.String()),
+		slog.Group("counts", args...),
+	)
+}
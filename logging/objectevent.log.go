@@ -4,24 +4,40 @@ import (
 	"log/slog"
 
 	db "go-todo/db/sqlc"
+	"go-todo/util/jwt"
 )
 
 type ObjectEventSub int
 
 const (
-	ObjectEventSubList ObjectEventSub = iota
+	ObjectEventSubField ObjectEventSub = iota
+	ObjectEventSubList
+	ObjectEventSubState
+	ObjectEventSubTemplate
+	ObjectEventSubTimeEntry
 	ObjectEventSubTodo
 	ObjectEventSubUser
+	ObjectEventSubInviteCode
 )
 
 func (e ObjectEventSub) String() string {
 	switch e {
+	case ObjectEventSubField:
+		return "field"
 	case ObjectEventSubList:
 		return "list"
+	case ObjectEventSubState:
+		return "state"
+	case ObjectEventSubTemplate:
+		return "template"
+	case ObjectEventSubTimeEntry:
+		return "time_entry"
 	case ObjectEventSubTodo:
 		return "todo"
 	case ObjectEventSubUser:
 		return "user"
+	case ObjectEventSubInviteCode:
+		return "invite_code"
 	}
 	return "unknown"
 }
@@ -49,23 +65,28 @@ func (e ObjectEvent) String() string {
 	return "objectevent:unknown"
 }
 
-// Logs object crud events. Subjects are given like &db.<type>
+// Logs object crud events. Subjects are given like &db.<type>. Act is the
+// admin impersonating the actor, nil if there is none.
 func LogObjectEvent(
 	targetPath string,
 	srcIp string,
 	eventType ObjectEvent,
 	actor *db.User,
+	act *jwt.ActClaim,
 	subjectCurrent any,
 	subjectOld any,
 	subjectType ObjectEventSub,
 ) {
 	getActorData := func(id, username string, isAdmin bool) slog.Attr {
-		return slog.Group(
-			"actor",
+		data := []any{
 			slog.String("id", id),
 			slog.String("username", username),
 			slog.Bool("is_admin", isAdmin),
-		)
+		}
+		if act != nil {
+			data = append(data, actData(act))
+		}
+		return slog.Group("actor", data...)
 	}
 
 	getSubject := func(subCur, subOld any) slog.Attr {
@@ -103,6 +124,13 @@ func LogObjectEvent(
 				)
 				groupOld = &gOld
 			}
+		case *db.TodoFieldValue:
+			gCur := slog.Group(
+				curKey,
+				slog.String("todo_id", sc.TodoID),
+				slog.String("field_id", sc.FieldID),
+			)
+			groupCurrent = &gCur
 		case *db.List:
 			gCur := slog.Group(
 				curKey,
@@ -135,6 +163,123 @@ func LogObjectEvent(
 				slog.String("ids", ids),
 			)
 			groupCurrent = &gCur
+		case *db.ListField:
+			gCur := slog.Group(
+				curKey,
+				slog.String("id", sc.ID),
+				slog.String("list_id", sc.ListID),
+				slog.String("name", sc.Name),
+				slog.String("field_type", sc.FieldType),
+			)
+			groupCurrent = &gCur
+			if subOld != nil {
+				so := subOld.(*db.ListField)
+				gOld := slog.Group(
+					oldKey,
+					slog.String("id", so.ID),
+					slog.String("list_id", so.ListID),
+					slog.String("name", so.Name),
+					slog.String("field_type", so.FieldType),
+				)
+				groupOld = &gOld
+			}
+		case *db.ListState:
+			gCur := slog.Group(
+				curKey,
+				slog.String("id", sc.ID),
+				slog.String("list_id", sc.ListID),
+				slog.String("name", sc.Name),
+				slog.Bool("is_done", sc.IsDone),
+			)
+			groupCurrent = &gCur
+			if subOld != nil {
+				so := subOld.(*db.ListState)
+				gOld := slog.Group(
+					oldKey,
+					slog.String("id", so.ID),
+					slog.String("list_id", so.ListID),
+					slog.String("name", so.Name),
+					slog.Bool("is_done", so.IsDone),
+				)
+				groupOld = &gOld
+			}
+		case *db.ListTemplate:
+			gCur := slog.Group(
+				curKey,
+				slog.String("id", sc.ID),
+				slog.String("title", sc.Title),
+				slog.Bool("is_global", sc.IsGlobal),
+			)
+			groupCurrent = &gCur
+		case []db.ListTemplate:
+			ids := ""
+			for i, template := range sc {
+				if i != 0 {
+					ids = ids + ","
+				}
+				ids = ids + template.ID
+			}
+			gCur := slog.Group(
+				curKey,
+				slog.String("ids", ids),
+			)
+			groupCurrent = &gCur
+		case *db.TimeEntry:
+			gCur := slog.Group(
+				curKey,
+				slog.String("id", sc.ID),
+				slog.String("todo_id", sc.TodoID),
+				slog.String("user_id", sc.UserID),
+				slog.String("started_at", sc.StartedAt.Time.String()),
+				slog.String("ended_at", sc.EndedAt.Time.String()),
+			)
+			groupCurrent = &gCur
+			if subOld != nil {
+				so := subOld.(*db.TimeEntry)
+				gOld := slog.Group(
+					oldKey,
+					slog.String("id", so.ID),
+					slog.String("todo_id", so.TodoID),
+					slog.String("user_id", so.UserID),
+					slog.String("started_at", so.StartedAt.Time.String()),
+					slog.String("ended_at", so.EndedAt.Time.String()),
+				)
+				groupOld = &gOld
+			}
+		case []db.TimeEntry:
+			ids := ""
+			for i, entry := range sc {
+				if i != 0 {
+					ids = ids + ","
+				}
+				ids = ids + entry.ID
+			}
+			gCur := slog.Group(
+				curKey,
+				slog.String("ids", ids),
+			)
+			groupCurrent = &gCur
+		case []db.ListUsersRow:
+			ids := ""
+			for i, user := range sc {
+				if i != 0 {
+					ids = ids + ","
+				}
+				ids = ids + user.ID
+			}
+			gCur := slog.Group(
+				curKey,
+				slog.String("ids", ids),
+			)
+			groupCurrent = &gCur
+		case *db.InviteCode:
+			gCur := slog.Group(
+				curKey,
+				slog.String("id", sc.ID),
+				slog.Int("max_uses", int(sc.MaxUses)),
+				slog.String("expires_at", sc.ExpiresAt.Time.String()),
+			)
+			groupCurrent = &gCur
 		case *db.CreateUserRow:
 			gCur := slog.Group(
 				curKey,
@@ -153,6 +298,24 @@ func LogObjectEvent(
 				)
 				groupOld = &gOld
 			}
+		case *db.User:
+			// Never the password hash.
+			userData := func(key string, user *db.User) slog.Attr {
+				return slog.Group(
+					key,
+					slog.String("id", user.ID),
+					slog.String("username", user.Username),
+					slog.Bool("is_admin", user.IsAdmin),
+					slog.String("email", user.Email.String),
+					slog.Bool("email_verified", user.EmailVerifiedAt.Valid),
+				)
+			}
+			gCur := userData(curKey, sc)
+			groupCurrent = &gCur
+			if so, ok := subOld.(*db.User); ok && so != nil {
+				gOld := userData(oldKey, so)
+				groupOld = &gOld
+			}
 		default:
 			group := slog.String(curKey, "nil")
 			groupCurrent = &group
@@ -21,6 +21,21 @@ const (
 	SecurityEventJwtUserUnknown
 	SecurityEventJwtUnknown
 	SecurityEventLoginToUnknownUsername
+	SecurityEventJwtRevoked
+	SecurityEventTotpFailed
+	SecurityEventLoginLockout
+	SecurityEventLoginThrottled
+	SecurityEventRateLimited
+	SecurityEventPasswordResetUnknownEmail
+	SecurityEventPasswordResetTokenInvalid
+	SecurityEventOidcFailed
+	SecurityEventPersonalTokenInvalid
+	SecurityEventPersonalTokenScopeDenied
+	SecurityEventSessionFingerprintMismatch
+	SecurityEventCsrfInvalid
+	SecurityEventEmailVerificationTokenInvalid
+	SecurityEventIntrospectionClientInvalid
+	SecurityEventInviteCodeInvalid
 )
 
 func (s SecurityEventName) String() string {
@@ -37,6 +52,36 @@ func (s SecurityEventName) String() string {
 		return "jwt-reuse"
 	case SecurityEventJwtUnknown:
 		return "jwt-unknown"
+	case SecurityEventJwtRevoked:
+		return "jwt-revoked-use"
+	case SecurityEventTotpFailed:
+		return "totp-failed"
+	case SecurityEventLoginLockout:
+		return "login-lockout"
+	case SecurityEventLoginThrottled:
+		return "login-throttled"
+	case SecurityEventRateLimited:
+		return "rate-limit-exceeded"
+	case SecurityEventPasswordResetUnknownEmail:
+		return "password-reset-unknown-email"
+	case SecurityEventPasswordResetTokenInvalid:
+		return "password-reset-token-invalid"
+	case SecurityEventOidcFailed:
+		return "oidc-failed"
+	case SecurityEventPersonalTokenInvalid:
+		return "personal-token-invalid"
+	case SecurityEventPersonalTokenScopeDenied:
+		return "personal-token-scope-denied"
+	case SecurityEventSessionFingerprintMismatch:
+		return "session-fingerprint-mismatch"
+	case SecurityEventCsrfInvalid:
+		return "csrf-invalid"
+	case SecurityEventEmailVerificationTokenInvalid:
+		return "email-verification-token-invalid"
+	case SecurityEventIntrospectionClientInvalid:
+		return "introspection-client-invalid"
+	case SecurityEventInviteCodeInvalid:
+		return "invite-code-invalid"
 	}
 	return "unknown"
 }
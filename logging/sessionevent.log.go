@@ -10,6 +10,16 @@ const (
 	SessionEventTypeLogin SessionEventType = iota
 	SessionEventTypeLogout
 	SessionEventTypeRefresh
+	SessionEventTypeRevoke
+	SessionEventTypeTotpDisable
+	SessionEventTypeTotpEnable
+	SessionEventTypeUnlock
+	SessionEventTypePasswordReset
+	SessionEventTypeOidcLink
+	SessionEventTypePersonalTokenCreate
+	SessionEventTypePersonalTokenRevoke
+	SessionEventTypeImpersonate
+	SessionEventTypeLdapLink
 )
 
 func (s SessionEventType) String() string {
@@ -20,6 +30,26 @@ func (s SessionEventType) String() string {
 		return "session:logout"
 	case SessionEventTypeRefresh:
 		return "session:refresh"
+	case SessionEventTypeRevoke:
+		return "session:revoke"
+	case SessionEventTypeTotpDisable:
+		return "session:totp-disable"
+	case SessionEventTypeTotpEnable:
+		return "session:totp-enable"
+	case SessionEventTypeUnlock:
+		return "session:unlock"
+	case SessionEventTypePasswordReset:
+		return "session:password-reset"
+	case SessionEventTypeOidcLink:
+		return "session:oidc-link"
+	case SessionEventTypePersonalTokenCreate:
+		return "session:personal-token-create"
+	case SessionEventTypePersonalTokenRevoke:
+		return "session:personal-token-revoke"
+	case SessionEventTypeImpersonate:
+		return "session:impersonate"
+	case SessionEventTypeLdapLink:
+		return "session:ldap-link"
 	}
 	return "unknown"
 }
@@ -43,3 +73,49 @@ func LogSessionEvent(
 		),
 	)
 }
+
+// Logs revoked sessions. Actor is the user who revoked the sessions of the
+// user with username.
+func LogSessionRevokeEvent(
+	targetPath string,
+	actor string,
+	username string,
+	families []string,
+	srcIp string,
+) {
+	LogAuditEvent(
+		true,
+		targetPath,
+		srcIp,
+		SessionEventTypeRevoke.String(),
+		slog.String("actor", actor),
+		slog.Group(
+			"target",
+			slog.String("username", username),
+			slog.Any("families", families),
+		),
+	)
+}
+
+// Logs an impersonation token being issued. Actor is the admin who will act
+// as the user with username.
+func LogImpersonationEvent(
+	targetPath string,
+	actor string,
+	username string,
+	jti string,
+	srcIp string,
+) {
+	LogAuditEvent(
+		true,
+		targetPath,
+		srcIp,
+		SessionEventTypeImpersonate.String(),
+		slog.String("actor", actor),
+		slog.Group(
+			"target",
+			slog.String("username", username),
+			slog.String("jti", jti),
+		),
+	)
+}
@@ -11,6 +11,7 @@ const (
 	TokenEventTypeAccess TokenEventType = iota
 	TokenEventTypeUse
 	TokenEventtypeCreate
+	TokenEventTypeIntrospect
 )
 
 func (t TokenEventType) String() string {
@@ -21,6 +22,8 @@ func (t TokenEventType) String() string {
 		return "token:create"
 	case TokenEventTypeUse:
 		return "token:use"
+	case TokenEventTypeIntrospect:
+		return "token:introspect"
 	}
 	return "unknown"
 }
@@ -33,21 +36,24 @@ func LogTokenEvent(
 	token *jwt.GtClaims,
 ) {
 	if token != nil {
+		tokenData := []any{
+			slog.String("sub", token.Subject),
+			slog.Bool("is_admin", token.IsAdmin),
+			slog.String("jti", token.ID),
+			slog.String("issuer", token.Issuer),
+			slog.String("issued_at", token.IssuedAt.String()),
+			slog.String("family", token.Family),
+			slog.String("expires_at", token.ExpiresAt.String()),
+		}
+		if token.Act != nil {
+			tokenData = append(tokenData, actData(token.Act))
+		}
 		LogAuditEvent(
 			success,
 			targetPath,
 			srcIp,
 			eventType.String(),
-			slog.Group(
-				"token",
-				slog.String("sub", token.Subject),
-				slog.Bool("is_admin", token.IsAdmin),
-				slog.String("jti", token.ID),
-				slog.String("issuer", token.Issuer),
-				slog.String("issued_at", token.IssuedAt.String()),
-				slog.String("family", token.Family),
-				slog.String("expires_at", token.ExpiresAt.String()),
-			),
+			slog.Group("token", tokenData...),
 		)
 	} else {
 		LogAuditEvent(
@@ -58,3 +64,12 @@ func LogTokenEvent(
 		)
 	}
 }
+
+// The real identity behind an impersonation token.
+func actData(act *jwt.ActClaim) slog.Attr {
+	return slog.Group(
+		"act",
+		slog.String("sub", act.Subject),
+		slog.String("username", act.Username),
+	)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"os"
 	"runtime"
 	"time"
 
@@ -11,13 +12,30 @@ import (
 
 	db "go-todo/db/sqlc"
 	"go-todo/features/auth"
+	"go-todo/features/listtemplate"
+	"go-todo/features/stats"
+	"go-todo/features/timeentry"
 	"go-todo/features/todo"
 	"go-todo/features/user"
 	"go-todo/logging"
 	"go-todo/middleware"
+	"go-todo/util/authcookie"
+	"go-todo/util/authn"
 	"go-todo/util/config"
+	"go-todo/util/fingerprint"
+	"go-todo/util/jwt"
+	"go-todo/util/loginguard"
+	"go-todo/util/mailer"
+	"go-todo/util/oidc"
+	"go-todo/util/passwd"
+	"go-todo/util/pat"
+	"go-todo/util/ratelimit"
+	"go-todo/util/registration"
+	"go-todo/util/revocation"
+	"go-todo/util/tokengc"
+	"go-todo/util/validate"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var ctx context.Context
@@ -33,7 +51,37 @@ func main() {
 		return
 	}
 
-	conn, err := pgx.Connect(context.Background(), config.DbUrl)
+	if err := validate.Configure(config); err != nil {
+		_, file, line, _ := runtime.Caller(1)
+		logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Failed to configure validation.")
+		return
+	}
+	loginguard.Configure(config)
+	if _, err := fingerprint.ParsePolicy(config.SessionFingerprintPolicy); err != nil {
+		_, file, line, _ := runtime.Caller(1)
+		logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Failed to configure sessions.")
+		return
+	}
+	if err := passwd.Configure(config); err != nil {
+		_, file, line, _ := runtime.Caller(1)
+		logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Failed to configure password hashing.")
+		return
+	}
+	if err := authcookie.Configure(config); err != nil {
+		_, file, line, _ := runtime.Caller(1)
+		logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Failed to configure auth cookies.")
+		return
+	}
+
+	if err := registration.Configure(config); err != nil {
+		_, file, line, _ := runtime.Caller(1)
+		logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Failed to configure registration.")
+		return
+	}
+
+	// Requests run concurrently, so each query and transaction takes its own
+	// connection from the pool.
+	pool, err := pgxpool.New(context.Background(), config.DbUrl)
 	if err != nil {
 		_, file, line, _ := runtime.Caller(1)
 		logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Failed to connect to database.")
@@ -42,16 +90,105 @@ func main() {
 		fmt.Println("Connected to database")
 	}
 
-	defer conn.Close(ctx)
+	defer pool.Close()
+
+	mydb := db.New(pool)
+
+	hashFormats, err := mydb.GetPasswordHashFormats(context.Background())
+	if err == nil {
+		err = passwd.ConfigureDummy(hashFormats)
+	}
+	if err != nil {
+		_, file, line, _ := runtime.Caller(1)
+		logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Failed to configure password hashing.")
+		return
+	}
+
+	if err := authn.Configure(config, mydb); err != nil {
+		_, file, line, _ := runtime.Caller(1)
+		logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Failed to configure authentication.")
+		return
+	}
+
+	if ran, err := runCommand(os.Args[1:], mydb, config); ran {
+		if err != nil {
+			_, file, line, _ := runtime.Caller(1)
+			logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Command failed.")
+		}
+		return
+	}
+
+	if admin, err := registration.BootstrapAdmin(context.Background(), config, mydb); err != nil {
+		_, file, line, _ := runtime.Caller(1)
+		logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Failed to create the admin.")
+		return
+	} else if admin != nil {
+		fmt.Printf("Created admin %v\n", admin.Username)
+	}
+
+	// Loaded after commands run so the keys command works before a key exists.
+	if err := jwt.ConfigureKeys(config); err != nil {
+		_, file, line, _ := runtime.Caller(1)
+		logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Failed to load jwt signing keys.")
+		return
+	}
+
+	if config.TokenGcInterval > 0 {
+		tokengc.New(mydb, config).Start(context.Background())
+	}
+
+	pat.SetStore(pat.NewPostgresStore(mydb))
 
-	mydb := db.New(conn)
+	switch config.RevocationStore {
+	case "memory":
+	case "postgres":
+		revocation.SetStore(revocation.NewPostgresStore(mydb))
+	default:
+		logging.LogError(
+			fmt.Errorf("unknown revocation store: %v", config.RevocationStore),
+			"main.go",
+			"Failed to configure token revocation.",
+		)
+		return
+	}
+
+	switch config.RateLimitStore {
+	case "memory":
+	case "postgres":
+		ratelimit.SetStore(ratelimit.NewPostgresStore(mydb))
+	default:
+		logging.LogError(
+			fmt.Errorf("unknown rate limit store: %v", config.RateLimitStore),
+			"main.go",
+			"Failed to configure rate limiting.",
+		)
+		return
+	}
+
+	mailer, err := mailer.New(config)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(1)
+		logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Failed to configure mail.")
+		return
+	}
+
+	var oidcProvider *oidc.Provider
+	if config.OidcEnabled {
+		oidcProvider = oidc.NewProvider(oidc.SettingsFromConfig(config), nil)
+	}
 
-	authController := auth.NewController(mydb, ctx)
+	authController := auth.NewController(mydb, mailer, oidcProvider, ctx)
 	authRoutes := auth.NewRoutes(authController)
-	userController := user.NewController(mydb, ctx)
+	userController := user.NewController(pool, mydb, mailer, ctx)
 	userRoutes := user.NewRoutes(userController)
 	listController := todo.NewController(mydb, ctx)
 	listRoutes := todo.NewRoutes(listController)
+	timeEntryController := timeentry.NewController(mydb, ctx)
+	timeEntryRoutes := timeentry.NewRoutes(timeEntryController)
+	statsController := stats.NewController(mydb, ctx)
+	statsRoutes := stats.NewRoutes(statsController)
+	templateController := listtemplate.NewController(pool, mydb, ctx)
+	templateRoutes := listtemplate.NewRoutes(templateController)
 
 	router := gin.Default()
 
@@ -70,14 +207,22 @@ func main() {
 		)
 	}))
 
+	authRoutes.RegisterWellKnown(router.Group("/.well-known"))
 	{
-		v1 := router.Group("/api/v1")
+		v1 := router.Group("/api/v1", middleware.CsrfMiddleware())
 		v1.GET("/status", func(ctx *gin.Context) {
 			ctx.JSON(200, gin.H{"status": "ok"})
 		})
-		authRoutes.Register(v1)
-		userRoutes.Register(v1)
-		listRoutes.Register(v1)
+		// Each route group is registered on a group carrying its rate limit.
+		authRoutes.Register(v1.Group("", middleware.RateLimitMiddleware(ratelimit.AuthPolicy(config))))
+		listGroup := v1.Group("", middleware.RateLimitMiddleware(ratelimit.ListPolicy(config)))
+		listRoutes.Register(listGroup)
+		timeEntryRoutes.Register(listGroup)
+		defaultGroup := v1.Group("", middleware.RateLimitMiddleware(ratelimit.DefaultPolicy(config)))
+		userRoutes.Register(defaultGroup)
+		authRoutes.RegisterIntrospection(defaultGroup)
+		statsRoutes.Register(defaultGroup)
+		templateRoutes.Register(defaultGroup)
 	}
 
 	slog.Info("Starting server.")
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"fmt"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/util/authcookie"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Checks the double submit CSRF token of state changing requests that carry a
+// session cookie. Requests with an Authorization header are left alone,
+// browsers don't add it on their own.
+func CsrfMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if c.GetHeader("Authorization") != "" || !authcookie.HasSessionCookie(c) {
+			c.Next()
+			return
+		}
+
+		if !authcookie.CheckCsrf(c) {
+			logging.LogSecurityEvent(
+				logging.SecurityScoreHigh,
+				logging.SecurityEventCsrfInvalid,
+				c.FullPath(),
+				"",
+				c.ClientIP(),
+			)
+			c.Error(
+				fmt.Errorf("%w: missing or invalid csrf token", gterrors.ErrForbidden),
+			).SetType(gterrors.GetGinErrorType())
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"go-todo/gterrors"
 	"go-todo/logging"
 	"runtime"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/golang-jwt/jwt/v5"
@@ -26,6 +27,8 @@ const (
 	StatusMessageMalformedBody
 	StatusMessageNotFound
 	StatusMessagePasswordUnsatisfied
+	StatusMessageTimerRunning
+	StatusMessageTooManyRequests
 	StatusMessageUnauthorized
 	StatusMessageUniqueViolation
 	StatusMessageUsernameUnsatisfied
@@ -45,6 +48,10 @@ func (t StatusMessage) String() string {
 		return "not-found"
 	case StatusMessagePasswordUnsatisfied:
 		return "password-unsatisfied"
+	case StatusMessageTimerRunning:
+		return "timer-running"
+	case StatusMessageTooManyRequests:
+		return "too-many-requests"
 	case StatusMessageUnauthorized:
 		return "unauthorized"
 	case StatusMessageUniqueViolation:
@@ -69,6 +76,7 @@ func ErrorHandlerMiddleware() gin.HandlerFunc {
 		var params *ResponseParams
 		var authError *gterrors.GtAuthError
 		var internalError *gterrors.GtInternalError
+		var tooManyRequestsError *gterrors.GtTooManyRequestsError
 		var validationError *gterrors.GtValidationError
 		switch {
 		// Malformed requests
@@ -82,8 +90,13 @@ func ErrorHandlerMiddleware() gin.HandlerFunc {
 			params = &ResponseParams{403, StatusMessageForbidden.String(), err.Error()}
 		case errors.Is(err, gterrors.ErrUniqueViolation):
 			params = &ResponseParams{409, StatusMessageUniqueViolation.String(), err.Error()}
+		case errors.Is(err, gterrors.ErrTimerRunning):
+			params = &ResponseParams{409, StatusMessageTimerRunning.String(), err.Error()}
 		case errors.Is(err, gterrors.ErrNotFound):
 			params = &ResponseParams{404, StatusMessageNotFound.String(), err.Error()}
+		case errors.As(err, &tooManyRequestsError):
+			c.Header("Retry-After", strconv.Itoa(gterrors.RetryAfterSeconds(tooManyRequestsError.RetryAfter)))
+			params = &ResponseParams{429, StatusMessageTooManyRequests.String(), err.Error()}
 		case errors.As(err, &validationError):
 			params = &ResponseParams{
 				400,
@@ -112,6 +125,8 @@ func ErrorHandlerMiddleware() gin.HandlerFunc {
 				statusMessage = StatusMessageUnauthorized.String()
 			case gterrors.GtAuthErrorReasonTokenReuse:
 				statusMessage = StatusMessageUnauthorized.String()
+			case gterrors.GtAuthErrorReasonTokenRevoked:
+				statusMessage = StatusMessageUnauthorized.String()
 			case gterrors.GtAuthErrorReasonUsernameInvalid:
 				statusMessage = StatusMessageInvalidCredentials.String()
 			case gterrors.GtAuthErrorReasonInternalError:
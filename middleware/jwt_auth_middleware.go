@@ -5,17 +5,32 @@ import (
 	"fmt"
 	"go-todo/gterrors"
 	"go-todo/logging"
+	"go-todo/util/authcookie"
 	jwtUtil "go-todo/util/jwt"
+	"go-todo/util/pat"
+	"go-todo/util/revocation"
 	"runtime"
 
 	"github.com/gin-gonic/gin"
 )
 
-// Tries to extract the JWT from Authorization header. Returns an error status
+// Tries to extract the JWT from Authorization header, or from the access
+// cookie when there is no header. Returns an error status
 // to the client if it fails.
 func JwtAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		token, err := jwtUtil.DecodeTokenFromHeader(c)
+		if pat.IsPersonalAccessToken(getBearerToken(c)) {
+			c.Error(
+				gterrors.NewGtAuthError(
+					gterrors.GtAuthErrorReasonTokenInvalid,
+					errors.New("personal access tokens can't be used for this route"),
+				),
+			).SetType(gterrors.GetGinErrorType())
+			c.Abort()
+			return
+		}
+
+		token, err := decodeAccessToken(c)
 		if err != nil {
 			ginType := gterrors.GetGinErrorType()
 			var jwtErr *jwtUtil.JwtDecodeError
@@ -54,12 +69,97 @@ func JwtAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		revoked, err := revocation.IsRevoked(c, token)
+		if err != nil {
+			_, file, line, _ := runtime.Caller(0)
+			c.Error(
+				gterrors.NewGtInternalError(
+					fmt.Errorf("failed to check token revocation: %w", err),
+					fmt.Sprintf("%v: %d", file, line),
+					500,
+				),
+			)
+			c.Abort()
+			return
+		} else if revoked {
+			logging.LogTokenEvent(false, c.FullPath(), logging.TokenEventTypeAccess, c.RemoteIP(), token)
+			logging.LogSecurityEvent(
+				logging.SecurityScoreMedium,
+				logging.SecurityEventJwtRevoked,
+				c.FullPath(),
+				token.Username,
+				c.ClientIP(),
+			)
+			c.Error(
+				gterrors.NewGtAuthError(
+					gterrors.GtAuthErrorReasonTokenRevoked,
+					errors.New("access token has been revoked"),
+				),
+			).SetType(gterrors.GetGinErrorType())
+			c.Abort()
+			return
+		}
+
 		logging.LogTokenEvent(true, c.FullPath(), logging.TokenEventTypeAccess, c.RemoteIP(), token)
 
+		c.Set("x-token-jti", token.ID)
+		c.Set("x-token-family", token.Family)
+		c.Set("x-token-expires-at", token.ExpiresAt.Time)
 		c.Set("x-token-username", token.Username)
 		c.Set("x-token-user-id", token.Subject)
 		c.Set("x-token-is-admin", token.IsAdmin)
+		if token.Act != nil {
+			c.Set("x-token-act", token.Act)
+		}
+
+		c.Next()
+	}
+}
+
+// Runs JwtAuthMiddleware if the request has an access token and lets it
+// through without one. Handlers tell the cases apart with
+// mycontext.GetTokenVariables.
+func OptionalJwtAuthMiddleware() gin.HandlerFunc {
+	auth := JwtAuthMiddleware()
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" && authcookie.AccessToken(c) == "" {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
 
+// Rejects impersonation tokens. Used after JwtAuthMiddleware on routes that
+// change the account itself, so an admin acting as a user can look around
+// but can't take the account over.
+func DenyImpersonationMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if act, ok := c.Get("x-token-act"); ok {
+			logging.LogSecurityEvent(
+				logging.SecurityScoreMedium,
+				logging.SecurityEventForbiddenAction,
+				c.FullPath(),
+				c.GetString("x-token-username"),
+				act.(*jwtUtil.ActClaim).Username,
+			)
+			c.Error(
+				fmt.Errorf("%w: not allowed while impersonating", gterrors.ErrForbidden),
+			).SetType(gterrors.GetGinErrorType())
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
+
+// Decodes the access token of the request. The Authorization header wins over
+// the access cookie, so bearer clients work the same with cookies enabled.
+func decodeAccessToken(c *gin.Context) (*jwtUtil.GtClaims, error) {
+	if c.GetHeader("Authorization") == "" {
+		if token := authcookie.AccessToken(c); token != "" {
+			return jwtUtil.DecodeAccessToken(token)
+		}
+	}
+	return jwtUtil.DecodeTokenFromHeader(c)
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"fmt"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/util/ratelimit"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limits the number of requests a client can make in the policy's window.
+// Requests with a valid access token are counted per user, others per IP.
+// Responses carry RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset
+// headers. Requests over the limit are answered with 429.
+func RateLimitMiddleware(policy ratelimit.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !policy.Enabled() {
+			c.Next()
+			return
+		}
+
+		// The token is only used to pick the key. JwtAuthMiddleware still
+		// decides whether the request is authenticated.
+		client := "ip:" + c.ClientIP()
+		if token, err := decodeAccessToken(c); err == nil {
+			client = "user:" + token.Subject
+		}
+
+		hits, resetAt, err := ratelimit.Hit(c, policy.Name+":"+client, policy.Window)
+		if err != nil {
+			_, file, line, _ := runtime.Caller(0)
+			c.Error(
+				gterrors.NewGtInternalError(
+					fmt.Errorf("failed to count request: %w", err),
+					fmt.Sprintf("%v: %d", file, line),
+					500,
+				),
+			)
+			c.Abort()
+			return
+		}
+
+		resetIn := time.Until(resetAt)
+		c.Header("RateLimit-Limit", strconv.Itoa(policy.Requests))
+		c.Header("RateLimit-Remaining", strconv.Itoa(max(policy.Requests-hits, 0)))
+		c.Header("RateLimit-Reset", strconv.Itoa(gterrors.RetryAfterSeconds(resetIn)))
+		if hits <= policy.Requests {
+			c.Next()
+			return
+		}
+
+		// Logged when the limit is first exceeded in a window and again if the
+		// client keeps going until twice the limit.
+		if hits == policy.Requests+1 || hits == 2*policy.Requests {
+			score := logging.SecurityScoreLow
+			if hits == 2*policy.Requests {
+				score = logging.SecurityScoreMedium
+			}
+			logging.LogSecurityEvent(
+				score,
+				logging.SecurityEventRateLimited,
+				c.FullPath(),
+				client,
+				c.ClientIP(),
+			)
+		}
+		c.Error(
+			gterrors.NewGtTooManyRequestsError(
+				resetIn,
+				fmt.Errorf("rate limit of %v requests per %v exceeded", policy.Requests, policy.Window),
+			),
+		).SetType(gin.ErrorTypePublic)
+		c.Abort()
+	}
+}
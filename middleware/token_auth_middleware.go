@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"go-todo/gterrors"
+	"go-todo/logging"
+	"go-todo/util/pat"
+	"net/http"
+	"runtime"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Accepts personal access tokens in addition to jwts. Personal access tokens
+// need the read scope of the resource for GET requests and the write scope
+// for everything else. Routes that don't use this middleware only accept
+// jwts.
+func TokenAuthMiddleware(resource string) gin.HandlerFunc {
+	jwtAuth := JwtAuthMiddleware()
+	return func(c *gin.Context) {
+		token := getBearerToken(c)
+		if !pat.IsPersonalAccessToken(token) {
+			jwtAuth(c)
+			return
+		}
+
+		personalToken, err := pat.Authenticate(c, token)
+		if err != nil {
+			if errors.Is(err, pat.ErrTokenInvalid) {
+				logging.LogSecurityEvent(
+					logging.SecurityScoreMedium,
+					logging.SecurityEventPersonalTokenInvalid,
+					c.FullPath(),
+					"",
+					c.ClientIP(),
+				)
+				c.Error(
+					gterrors.NewGtAuthError(gterrors.GtAuthErrorReasonTokenInvalid, err),
+				).SetType(gterrors.GetGinErrorType())
+				c.Abort()
+				return
+			}
+			_, file, line, _ := runtime.Caller(0)
+			c.Error(
+				gterrors.NewGtInternalError(
+					fmt.Errorf("failed to authenticate personal access token: %w", err),
+					fmt.Sprintf("%v: %d", file, line),
+					500,
+				),
+			)
+			c.Abort()
+			return
+		}
+
+		write := c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead
+		if scope := pat.Scope(resource, write); !personalToken.HasScope(scope) {
+			logging.LogSecurityEvent(
+				logging.SecurityScoreLow,
+				logging.SecurityEventPersonalTokenScopeDenied,
+				c.FullPath(),
+				personalToken.Username,
+				c.ClientIP(),
+			)
+			c.Error(
+				fmt.Errorf("%w: token is missing scope %v", gterrors.ErrForbidden, scope),
+			).SetType(gterrors.GetGinErrorType())
+			c.Abort()
+			return
+		}
+
+		// Personal access tokens never grant admin rights and don't belong to
+		// a session. Handlers check admin rights with
+		// mycontext.HasAdminRights, which looks at x-token-is-pat.
+		c.Set("x-token-username", personalToken.Username)
+		c.Set("x-token-user-id", personalToken.UserID)
+		c.Set("x-token-is-admin", false)
+		c.Set("x-token-is-pat", true)
+		c.Set("x-token-personal-id", personalToken.ID)
+
+		c.Next()
+	}
+}
+
+func getBearerToken(c *gin.Context) string {
+	token, found := strings.CutPrefix(c.Request.Header.Get("Authorization"), "Bearer ")
+	if !found {
+		return ""
+	}
+	return token
+}
@@ -3,13 +3,18 @@ package schemas
 type Login struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	// Shown in the session list, e.g. "Work laptop"
+	DeviceName string `json:"device_name"`
+	// Set the refresh token as a cookie instead of returning it
+	UseCookies bool `json:"use_cookies"`
 }
 
 type Refresh struct {
-	RefreshToken string `json:"refresh_token" binding:"required"`
+	// Read from the refresh cookie if empty
+	RefreshToken string `json:"refresh_token"`
 }
 
 type UpdatePassword struct {
 	OldPassword string `json:"old_password" binding:"required"`
 	NewPassword string `json:"new_password" binding:"required"`
-}
\ No newline at end of file
+}
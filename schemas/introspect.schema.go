@@ -0,0 +1,30 @@
+package schemas
+
+// Form encoded as in RFC 7662.
+type Introspect struct {
+	Token string `form:"token" binding:"required"`
+	// Ignored, the token type is told by the token.
+	TokenTypeHint string `form:"token_type_hint"`
+}
+
+type IntrospectActor struct {
+	Subject  string `json:"sub"`
+	Username string `json:"username"`
+}
+
+// IsAdmin is set for all active tokens, personal access tokens are never
+// admin. Scope lists the space separated scopes of personal access tokens.
+type IntrospectResponse struct {
+	Active    bool             `json:"active"`
+	TokenType string           `json:"token_type,omitempty"`
+	Subject   string           `json:"sub,omitempty"`
+	Username  string           `json:"username,omitempty"`
+	IsAdmin   *bool            `json:"is_admin,omitempty"`
+	Scope     string           `json:"scope,omitempty"`
+	Family    string           `json:"family,omitempty"`
+	Jti       string           `json:"jti,omitempty"`
+	Issuer    string           `json:"iss,omitempty"`
+	IssuedAt  int64            `json:"iat,omitempty"`
+	ExpiresAt int64            `json:"exp,omitempty"`
+	Act       *IntrospectActor `json:"act,omitempty"`
+}
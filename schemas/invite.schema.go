@@ -0,0 +1,19 @@
+package schemas
+
+import "time"
+
+type CreateInviteCode struct {
+	// Defaults to 1
+	MaxUses int32 `json:"max_uses"`
+	// Defaults to INVITE_CODE_LIFE_SPAN from now
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type ResponseInviteCode struct {
+	ID        string    `json:"id"`
+	CreatedBy string    `json:"created_by"`
+	MaxUses   int32     `json:"max_uses"`
+	Uses      int32     `json:"uses"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -9,3 +9,28 @@ type UpdateList struct {
 	Title       *string `json:"title"`
 	Description *string `json:"description"`
 }
+
+type CreateListState struct {
+	Name     string `json:"name" binding:"required"`
+	IsDone   bool   `json:"is_done"`
+	Position *int32 `json:"position"`
+}
+
+type UpdateListState struct {
+	Name     *string `json:"name"`
+	IsDone   *bool   `json:"is_done"`
+	Position *int32  `json:"position"`
+}
+
+type CreateListField struct {
+	Name      string   `json:"name" binding:"required"`
+	FieldType string   `json:"field_type" binding:"required"`
+	Options   []string `json:"options"`
+	Position  *int32   `json:"position"`
+}
+
+type UpdateListField struct {
+	Name     *string   `json:"name"`
+	Options  *[]string `json:"options"`
+	Position *int32    `json:"position"`
+}
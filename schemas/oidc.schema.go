@@ -0,0 +1,9 @@
+package schemas
+
+type OidcCallback struct {
+	Code       string `json:"code" binding:"required"`
+	State      string `json:"state" binding:"required"`
+	DeviceName string `json:"device_name"`
+	// Set the refresh token as a cookie instead of returning it
+	UseCookies bool `json:"use_cookies"`
+}
@@ -0,0 +1,10 @@
+package schemas
+
+type RequestPasswordReset struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type ConfirmPasswordReset struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
@@ -0,0 +1,19 @@
+package schemas
+
+import "time"
+
+type CreatePersonalToken struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+	// The token never expires if not set
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type ResponsePersonalToken struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package schemas
+
+import "time"
+
+type ResponseSession struct {
+	Family     string    `json:"family"`
+	DeviceName string    `json:"device_name"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// Where the session was created
+	CreatedIp        string `json:"created_ip"`
+	CreatedUserAgent string `json:"created_user_agent"`
+	// Where the session was last used
+	Ip        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+	Current   bool   `json:"current"`
+}
@@ -0,0 +1,30 @@
+package schemas
+
+import "time"
+
+type ResponseStatsBucket struct {
+	Start          time.Time `json:"start"`
+	CreatedCount   int64     `json:"created_count"`
+	CompletedCount int64     `json:"completed_count"`
+	// Completed todos per created todos in the bucket. Nil if nothing was
+	// created.
+	CompletionRate *float64 `json:"completion_rate"`
+}
+
+type ResponseStatsCollaborator struct {
+	UserID         string `json:"user_id"`
+	Username       string `json:"username"`
+	OpenCount      int64  `json:"open_count"`
+	CompletedCount int64  `json:"completed_count"`
+	OverdueCount   int64  `json:"overdue_count"`
+}
+
+type ResponseStats struct {
+	OpenCount            int64                       `json:"open_count"`
+	CompletedCount       int64                       `json:"completed_count"`
+	OverdueCount         int64                       `json:"overdue_count"`
+	AvgCompletionSeconds float64                     `json:"avg_completion_seconds"`
+	Bucket               string                      `json:"bucket"`
+	Buckets              []ResponseStatsBucket       `json:"buckets"`
+	Collaborators        []ResponseStatsCollaborator `json:"collaborators,omitempty"`
+}
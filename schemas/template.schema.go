@@ -0,0 +1,24 @@
+package schemas
+
+import "time"
+
+type CreateTemplate struct {
+	ListID      string  `json:"list_id" binding:"required"`
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	IsGlobal    bool    `json:"is_global"`
+	// Due dates of the todos are saved relative to this. Defaults to the
+	// creation time of the list.
+	Anchor *time.Time `json:"anchor"`
+}
+
+type InstantiateTemplate struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	// Relative due dates are shifted from this. Defaults to current time.
+	Anchor *time.Time `json:"anchor"`
+}
+
+type CloneList struct {
+	Title *string `json:"title"`
+}
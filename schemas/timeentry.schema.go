@@ -0,0 +1,9 @@
+package schemas
+
+import "time"
+
+type CreateTimeEntry struct {
+	StartedAt time.Time `json:"started_at" binding:"required"`
+	EndedAt   time.Time `json:"ended_at" binding:"required"`
+	Note      *string   `json:"note"`
+}
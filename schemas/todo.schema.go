@@ -14,4 +14,13 @@ type UpdateTodo struct {
 	Description    *string    `json:"description"`
 	CompleteBefore *time.Time `json:"complete_before"`
 	Completed      *bool      `json:"completed"`
+	StateID        *string    `json:"state_id"`
+}
+
+type TransitionTodo struct {
+	StateID string `json:"state_id" binding:"required"`
+}
+
+type SetTodoFieldValue struct {
+	Value any `json:"value"`
 }
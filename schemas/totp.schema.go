@@ -0,0 +1,18 @@
+package schemas
+
+type LoginTotp struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	// Either a code from the authenticator app or a recovery code
+	Code       string `json:"code" binding:"required"`
+	DeviceName string `json:"device_name"`
+	// Set the refresh token as a cookie instead of returning it
+	UseCookies bool `json:"use_cookies"`
+}
+
+type ConfirmTotp struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type DisableTotp struct {
+	Password string `json:"password" binding:"required"`
+}
@@ -5,12 +5,25 @@ import "time"
 type CreateUser struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
-	IsAdmin  bool   `json:"is_admin"`
+	// Only honored when an admin creates the user
+	IsAdmin bool `json:"is_admin"`
+	// Required when registration is invite only, unless an admin creates
+	// the user
+	InviteCode string `json:"invite_code"`
 }
 
 type UpdateUser struct {
 	Username string `json:"username" binding:"required"`
 	IsAdmin  *bool  `json:"is_admin" binding:"required"`
+	// Left as is when omitted. An empty string removes the address, a new
+	// one is only set once it's confirmed.
+	Email *string `json:"email"`
+	// The rest are also left as is when omitted.
+	DisplayName *string `json:"display_name"`
+	// IANA time zone name, e.g. "Europe/Helsinki"
+	Timezone *string `json:"timezone"`
+	// BCP 47 language tag, e.g. "en-US"
+	Locale *string `json:"locale"`
 }
 
 type ResponseUser struct {
@@ -18,4 +31,26 @@ type ResponseUser struct {
 	Username  string    `json:"username"`
 	IsAdmin   bool      `json:"is_admin"`
 	CreatedAt time.Time `json:"created_at"`
+	Email     string    `json:"email"`
+	// False until the owner has confirmed the address.
+	EmailVerified bool   `json:"email_verified"`
+	DisplayName   string `json:"display_name"`
+	Timezone      string `json:"timezone"`
+	Locale        string `json:"locale"`
+}
+
+type ConfirmEmail struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ImpersonateUser struct {
+	// Must be set to impersonate another admin
+	AllowAdmin bool `json:"allow_admin"`
+}
+
+type ListedUser struct {
+	ResponseUser
+	ListCount int64 `json:"list_count"`
+	// Sessions with a refresh token that can still be used
+	SessionCount int64 `json:"session_count"`
 }
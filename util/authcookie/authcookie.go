@@ -0,0 +1,158 @@
+package authcookie
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go-todo/util/config"
+	"go-todo/util/securetoken"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	RefreshCookie = "gt_refresh"
+	AccessCookie  = "gt_access"
+	// Readable by scripts so the frontend can copy it to CsrfHeader.
+	CsrfCookie = "gt_csrf"
+	CsrfHeader = "X-CSRF-Token"
+)
+
+type Mode string
+
+const (
+	// Tokens are only returned in response bodies.
+	ModeOff Mode = "off"
+	// The refresh token is a cookie, the access token is in the body and
+	// sent in the Authorization header.
+	ModeRefresh Mode = "refresh"
+	// Both tokens are cookies.
+	ModeAll Mode = "all"
+)
+
+type Settings struct {
+	Mode     Mode
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+	// Path of the refresh cookie. Only the auth routes need it.
+	RefreshPath string
+}
+
+var settings = Settings{Mode: ModeOff}
+
+// Reads the cookie settings from the config. Should be called once at
+// startup.
+func Configure(cfg *config.Config) error {
+	s := Settings{
+		Mode:        Mode(cfg.AuthCookieMode),
+		Domain:      cfg.AuthCookieDomain,
+		Secure:      cfg.AuthCookieSecure,
+		RefreshPath: "/api/v1/auth",
+	}
+	switch s.Mode {
+	case ModeOff, ModeRefresh, ModeAll:
+	default:
+		return fmt.Errorf("unknown auth cookie mode: %v", cfg.AuthCookieMode)
+	}
+	switch cfg.AuthCookieSameSite {
+	case "strict":
+		s.SameSite = http.SameSiteStrictMode
+	case "lax":
+		s.SameSite = http.SameSiteLaxMode
+	case "none":
+		s.SameSite = http.SameSiteNoneMode
+	default:
+		return fmt.Errorf("unknown auth cookie same site mode: %v", cfg.AuthCookieSameSite)
+	}
+	settings = s
+	return nil
+}
+
+func Enabled() bool {
+	return settings.Mode != ModeOff
+}
+
+// Tells whether the access token goes in the response body of a cookie
+// login, because it isn't a cookie itself.
+func AccessTokenInBody() bool {
+	return settings.Mode == ModeRefresh
+}
+
+func setCookie(c *gin.Context, name, value, path string, expiresAt time.Time, httpOnly bool) {
+	maxAge := int(time.Until(expiresAt).Seconds())
+	if value == "" {
+		maxAge = -1
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   settings.Domain,
+		Expires:  expiresAt,
+		MaxAge:   maxAge,
+		Secure:   settings.Secure,
+		HttpOnly: httpOnly,
+		SameSite: settings.SameSite,
+	})
+}
+
+// Sets the cookies of a new or refreshed session, including a new CSRF
+// token that lives as long as the refresh token.
+func SetSession(c *gin.Context, refreshToken string, refreshExpiresAt time.Time, accessToken string, accessExpiresAt time.Time) error {
+	csrfToken, _, err := securetoken.Generate()
+	if err != nil {
+		return fmt.Errorf("failed to generate csrf token: %w", err)
+	}
+	setCookie(c, RefreshCookie, refreshToken, settings.RefreshPath, refreshExpiresAt, true)
+	setCookie(c, CsrfCookie, csrfToken, "/", refreshExpiresAt, false)
+	if settings.Mode == ModeAll {
+		setCookie(c, AccessCookie, accessToken, "/", accessExpiresAt, true)
+	}
+	return nil
+}
+
+// Removes the session cookies, e.g. on logout.
+func Clear(c *gin.Context) {
+	setCookie(c, RefreshCookie, "", settings.RefreshPath, time.Unix(0, 0), true)
+	setCookie(c, CsrfCookie, "", "/", time.Unix(0, 0), false)
+	setCookie(c, AccessCookie, "", "/", time.Unix(0, 0), true)
+}
+
+func cookie(c *gin.Context, name string) string {
+	if !Enabled() {
+		return ""
+	}
+	value, err := c.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+func RefreshToken(c *gin.Context) string {
+	return cookie(c, RefreshCookie)
+}
+
+func AccessToken(c *gin.Context) string {
+	if settings.Mode != ModeAll {
+		return ""
+	}
+	return cookie(c, AccessCookie)
+}
+
+// Tells whether the request carries a session cookie, i.e. a browser could
+// have sent it on its own.
+func HasSessionCookie(c *gin.Context) bool {
+	return RefreshToken(c) != "" || AccessToken(c) != ""
+}
+
+// Double submit check: the header must repeat the CSRF cookie. Other sites
+// can make the browser send the cookie but can't read it.
+func CheckCsrf(c *gin.Context) bool {
+	expected := cookie(c, CsrfCookie)
+	got := c.GetHeader(CsrfHeader)
+	return expected != "" && subtle.ConstantTimeCompare([]byte(expected), []byte(got)) == 1
+}
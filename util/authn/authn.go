@@ -0,0 +1,106 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+
+	db "go-todo/db/sqlc"
+	"go-todo/logging"
+	"go-todo/util/config"
+)
+
+var (
+	// The authenticator doesn't know the username, the next one is asked.
+	ErrUnknownUser = errors.New("unknown user")
+	// The authenticator knows the username but the password is wrong. No
+	// other authenticator is asked.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// The authenticator couldn't be asked, the next one is.
+	ErrUnavailable = errors.New("authenticator unavailable")
+)
+
+const (
+	ProviderLocal = "local"
+	ProviderLdap  = "ldap"
+)
+
+// A user as an authenticator knows them.
+type Identity struct {
+	// Name of the authenticator
+	Provider string
+	// Set by the local authenticator, which authenticates users directly.
+	User *db.User
+	// Set by external authenticators. Issuer and Subject identify the user
+	// at the provider and link it to a local user.
+	Issuer   string
+	Subject  string
+	Username string
+	// Empty if the provider has none.
+	Email string
+}
+
+type Authenticator interface {
+	Name() string
+	// Returns ErrUnknownUser, ErrInvalidCredentials or ErrUnavailable if the
+	// password doesn't authenticate the user.
+	Authenticate(ctx context.Context, username, password string) (*Identity, error)
+}
+
+// Asked in order. Set by Configure.
+var authenticators []Authenticator
+
+// Builds the authenticators listed in AUTH_PROVIDERS. Should be called once
+// at startup.
+func Configure(cfg *config.Config, queries *db.Queries) error {
+	configured := []Authenticator{}
+	for _, name := range strings.Split(cfg.AuthProviders, ",") {
+		switch strings.TrimSpace(name) {
+		case ProviderLocal:
+			configured = append(configured, NewLocalAuthenticator(queries))
+		case ProviderLdap:
+			ldap, err := NewLdapAuthenticator(LdapSettingsFromConfig(cfg))
+			if err != nil {
+				return err
+			}
+			configured = append(configured, ldap)
+		default:
+			return fmt.Errorf("unknown auth provider: %v", name)
+		}
+	}
+	if len(configured) == 0 {
+		return errors.New("no auth providers configured")
+	}
+	authenticators = configured
+	return nil
+}
+
+// Asks the authenticators in order until one knows the username. Returns
+// ErrUnknownUser if none does, or ErrUnavailable if one that couldn't be
+// asked might have.
+func Authenticate(ctx context.Context, username, password string) (*Identity, error) {
+	var unavailable error
+	for _, authenticator := range authenticators {
+		identity, err := authenticator.Authenticate(ctx, username, password)
+		if err == nil {
+			identity.Provider = authenticator.Name()
+			return identity, nil
+		}
+		switch {
+		case errors.Is(err, ErrUnknownUser):
+			continue
+		case errors.Is(err, ErrUnavailable):
+			_, file, line, _ := runtime.Caller(0)
+			logging.LogError(err, fmt.Sprintf("%v: %d", file, line), "Authenticator is unavailable.")
+			unavailable = err
+			continue
+		}
+		return nil, err
+	}
+	if unavailable != nil {
+		return nil, unavailable
+	}
+	return nil, ErrUnknownUser
+}
@@ -0,0 +1,181 @@
+package authn
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-todo/util/config"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// Issuers of directory identities are the prefix and the base DN.
+const ldapIssuerPrefix = "ldap:"
+
+type LdapSettings struct {
+	Url string
+	// Only used with ldap:// urls, ldaps:// urls are always encrypted.
+	StartTls bool
+	// Allows sending passwords unencrypted with ldap:// urls without StartTls.
+	Insecure bool
+	// Used to search for the user. Searches anonymously if empty.
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	// %s is replaced with the escaped username, e.g. "(uid=%s)".
+	UserFilter string
+	// Attribute that identifies the user for good. The DN is used if empty.
+	IDAttribute    string
+	EmailAttribute string
+	Timeout        time.Duration
+}
+
+func LdapSettingsFromConfig(cfg *config.Config) LdapSettings {
+	return LdapSettings{
+		Url:            cfg.LdapUrl,
+		StartTls:       cfg.LdapStartTls,
+		Insecure:       cfg.LdapInsecure,
+		BindDN:         cfg.LdapBindDN,
+		BindPassword:   cfg.LdapBindPassword,
+		BaseDN:         cfg.LdapBaseDN,
+		UserFilter:     cfg.LdapUserFilter,
+		IDAttribute:    cfg.LdapIDAttribute,
+		EmailAttribute: cfg.LdapEmailAttribute,
+		Timeout:        time.Duration(cfg.LdapTimeout) * time.Second,
+	}
+}
+
+// Authenticates users by searching for them in a directory and binding as
+// the entry found with their password.
+type LdapAuthenticator struct {
+	settings LdapSettings
+	host     string
+	startTls bool
+	issuer   string
+}
+
+func NewLdapAuthenticator(settings LdapSettings) (*LdapAuthenticator, error) {
+	u, err := url.Parse(settings.Url)
+	if err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") {
+		return nil, fmt.Errorf("invalid ldap url: %v", settings.Url)
+	}
+	startTls := settings.StartTls && u.Scheme == "ldap"
+	if u.Scheme == "ldap" && !startTls && !settings.Insecure {
+		return nil, errors.New("ldap:// urls need StartTLS, passwords would be sent unencrypted")
+	}
+	if settings.BaseDN == "" {
+		return nil, errors.New("ldap base dn is not set")
+	}
+	if strings.Count(settings.UserFilter, "%s") != 1 {
+		return nil, fmt.Errorf("ldap user filter must contain %%s once: %v", settings.UserFilter)
+	}
+	// Links to users survive moving the server, but not the users.
+	return &LdapAuthenticator{
+		settings: settings,
+		host:     u.Hostname(),
+		startTls: startTls,
+		issuer:   ldapIssuerPrefix + settings.BaseDN,
+	}, nil
+}
+
+func (a *LdapAuthenticator) Name() string {
+	return ProviderLdap
+}
+
+func (a *LdapAuthenticator) connect() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(
+		a.settings.Url,
+		ldap.DialWithDialer(&net.Dialer{Timeout: a.settings.Timeout}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(a.settings.Timeout)
+	if a.startTls {
+		if err := conn.StartTLS(&tls.Config{ServerName: a.host}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if a.settings.BindDN != "" {
+		if err := conn.Bind(a.settings.BindDN, a.settings.BindPassword); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (a *LdapAuthenticator) Authenticate(ctx context.Context, username, password string) (*Identity, error) {
+	// An empty password would be an anonymous bind, which always succeeds.
+	if password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := a.connect()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to connect to ldap: %w", ErrUnavailable, err)
+	}
+	defer conn.Close()
+
+	attributes := []string{"dn"}
+	if a.settings.IDAttribute != "" {
+		attributes = append(attributes, a.settings.IDAttribute)
+	}
+	if a.settings.EmailAttribute != "" {
+		attributes = append(attributes, a.settings.EmailAttribute)
+	}
+	// Two are enough to tell that the filter isn't unique.
+	search := ldap.NewSearchRequest(
+		a.settings.BaseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		2,
+		int(a.settings.Timeout.Seconds()),
+		false,
+		fmt.Sprintf(a.settings.UserFilter, ldap.EscapeFilter(username)),
+		attributes,
+		nil,
+	)
+	result, err := conn.Search(search)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("%w: failed to search ldap: %w", ErrUnavailable, err)
+	}
+	switch {
+	case result == nil || len(result.Entries) == 0:
+		return nil, ErrUnknownUser
+	case len(result.Entries) > 1:
+		return nil, fmt.Errorf("ldap user filter matches more than one entry for %v", username)
+	}
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("%w: failed to bind as user: %w", ErrUnavailable, err)
+	}
+
+	subject := entry.DN
+	if a.settings.IDAttribute != "" {
+		subject = entry.GetAttributeValue(a.settings.IDAttribute)
+		if subject == "" {
+			return nil, fmt.Errorf("ldap entry %v has no %v", entry.DN, a.settings.IDAttribute)
+		}
+	}
+	identity := &Identity{
+		Issuer:   a.issuer,
+		Subject:  subject,
+		Username: username,
+	}
+	if a.settings.EmailAttribute != "" {
+		identity.Email = entry.GetAttributeValue(a.settings.EmailAttribute)
+	}
+	return identity, nil
+}
@@ -0,0 +1,52 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	db "go-todo/db/sqlc"
+	"go-todo/util/passwd"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Authenticates users with the password hashes in the database.
+type LocalAuthenticator struct {
+	queries *db.Queries
+}
+
+func NewLocalAuthenticator(queries *db.Queries) *LocalAuthenticator {
+	return &LocalAuthenticator{queries}
+}
+
+func (a *LocalAuthenticator) Name() string {
+	return ProviderLocal
+}
+
+func (a *LocalAuthenticator) Authenticate(ctx context.Context, username, password string) (*Identity, error) {
+	user, err := a.queries.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Takes as long as checking the password of a known user.
+			passwd.CompareDummy(password)
+			return nil, ErrUnknownUser
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !passwd.Compare(password, user.PasswordHash) {
+		// Users provisioned from the directory log in with their directory
+		// password, which the ldap authenticator checks.
+		fromLdap, err := a.queries.HasUserIdentityFromIssuer(ctx, db.HasUserIdentityFromIssuerParams{
+			UserID:       user.ID,
+			IssuerPrefix: ldapIssuerPrefix,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user identities: %w", err)
+		} else if fromLdap {
+			return nil, ErrUnknownUser
+		}
+		return nil, ErrInvalidCredentials
+	}
+	return &Identity{User: &user, Username: user.Username}, nil
+}
@@ -16,6 +16,109 @@ type Config struct {
 	RefreshTokenLifeSpan int    `mapstructure:"REFRESH_TOKEN_LIFE_SPAN"`
 	JwtAccessSecret      string `mapstructure:"JWT_ACCESS_SECRET"`
 	JwtRefreshSecret     string `mapstructure:"JWT_REFRESH_SECRET"`
+
+	TitleMaxLength         int    `mapstructure:"TITLE_MAX_LENGTH"`
+	DescriptionMaxLength   int    `mapstructure:"DESCRIPTION_MAX_LENGTH"`
+	UsernameMinLength      int    `mapstructure:"USERNAME_MIN_LENGTH"`
+	UsernameMaxLength      int    `mapstructure:"USERNAME_MAX_LENGTH"`
+	DisplayNameMaxLength   int    `mapstructure:"DISPLAY_NAME_MAX_LENGTH"`
+	PasswordMinLength      int    `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordMaxLength      int    `mapstructure:"PASSWORD_MAX_LENGTH"`
+	PasswordRequireLetter  bool   `mapstructure:"PASSWORD_REQUIRE_LETTER"`
+	PasswordRequireUpper   bool   `mapstructure:"PASSWORD_REQUIRE_UPPER"`
+	PasswordRequireNumber  bool   `mapstructure:"PASSWORD_REQUIRE_NUMBER"`
+	PasswordRequireSpecial bool   `mapstructure:"PASSWORD_REQUIRE_SPECIAL"`
+	PasswordDenyListFile   string `mapstructure:"PASSWORD_DENY_LIST_FILE"`
+	PasswordDenyUsername   bool   `mapstructure:"PASSWORD_DENY_USERNAME"`
+	PasswordHistorySize    int    `mapstructure:"PASSWORD_HISTORY_SIZE"`
+
+	PasswordHashAlgorithm     string `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	PasswordArgon2Memory      int    `mapstructure:"PASSWORD_ARGON2_MEMORY"`
+	PasswordArgon2Iterations  int    `mapstructure:"PASSWORD_ARGON2_ITERATIONS"`
+	PasswordArgon2Parallelism int    `mapstructure:"PASSWORD_ARGON2_PARALLELISM"`
+	PasswordBcryptCost        int    `mapstructure:"PASSWORD_BCRYPT_COST"`
+
+	RevocationStore string `mapstructure:"REVOCATION_STORE"`
+
+	TokenGcInterval  int `mapstructure:"TOKEN_GC_INTERVAL"`
+	TokenGcUsedGrace int `mapstructure:"TOKEN_GC_USED_GRACE"`
+	TokenGcBatchSize int `mapstructure:"TOKEN_GC_BATCH_SIZE"`
+
+	TotpIssuer            string `mapstructure:"TOTP_ISSUER"`
+	TotpChallengeLifeSpan int    `mapstructure:"TOTP_CHALLENGE_LIFE_SPAN"`
+
+	LoginFreeAttempts       int `mapstructure:"LOGIN_FREE_ATTEMPTS"`
+	LoginLockoutThreshold   int `mapstructure:"LOGIN_LOCKOUT_THRESHOLD"`
+	LoginIpFreeAttempts     int `mapstructure:"LOGIN_IP_FREE_ATTEMPTS"`
+	LoginIpLockoutThreshold int `mapstructure:"LOGIN_IP_LOCKOUT_THRESHOLD"`
+	LoginBackoffBase        int `mapstructure:"LOGIN_BACKOFF_BASE"`
+	LoginBackoffMax         int `mapstructure:"LOGIN_BACKOFF_MAX"`
+	LoginLockoutDuration    int `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+
+	RateLimitStore           string `mapstructure:"RATE_LIMIT_STORE"`
+	RateLimitAuthRequests    int    `mapstructure:"RATE_LIMIT_AUTH_REQUESTS"`
+	RateLimitAuthWindow      int    `mapstructure:"RATE_LIMIT_AUTH_WINDOW"`
+	RateLimitListRequests    int    `mapstructure:"RATE_LIMIT_LIST_REQUESTS"`
+	RateLimitListWindow      int    `mapstructure:"RATE_LIMIT_LIST_WINDOW"`
+	RateLimitDefaultRequests int    `mapstructure:"RATE_LIMIT_DEFAULT_REQUESTS"`
+	RateLimitDefaultWindow   int    `mapstructure:"RATE_LIMIT_DEFAULT_WINDOW"`
+
+	MailBackend  string `mapstructure:"MAIL_BACKEND"`
+	MailFrom     string `mapstructure:"MAIL_FROM"`
+	MailFile     string `mapstructure:"MAIL_FILE"`
+	SmtpHost     string `mapstructure:"SMTP_HOST"`
+	SmtpPort     int    `mapstructure:"SMTP_PORT"`
+	SmtpUsername string `mapstructure:"SMTP_USERNAME"`
+	SmtpPassword string `mapstructure:"SMTP_PASSWORD"`
+
+	PasswordResetLifeSpan int    `mapstructure:"PASSWORD_RESET_LIFE_SPAN"`
+	PasswordResetUrl      string `mapstructure:"PASSWORD_RESET_URL"`
+
+	OidcEnabled       bool   `mapstructure:"OIDC_ENABLED"`
+	OidcIssuer        string `mapstructure:"OIDC_ISSUER"`
+	OidcClientID      string `mapstructure:"OIDC_CLIENT_ID"`
+	OidcClientSecret  string `mapstructure:"OIDC_CLIENT_SECRET"`
+	OidcRedirectUrl   string `mapstructure:"OIDC_REDIRECT_URL"`
+	OidcScopes        string `mapstructure:"OIDC_SCOPES"`
+	OidcLinkByEmail   bool   `mapstructure:"OIDC_LINK_BY_EMAIL"`
+	OidcAutoProvision bool   `mapstructure:"OIDC_AUTO_PROVISION"`
+	OidcStateLifeSpan int    `mapstructure:"OIDC_STATE_LIFE_SPAN"`
+
+	JwtSigningAlgorithm string `mapstructure:"JWT_SIGNING_ALGORITHM"`
+	JwtKeyDir           string `mapstructure:"JWT_KEY_DIR"`
+
+	ImpersonationLifeSpan int `mapstructure:"IMPERSONATION_LIFE_SPAN"`
+
+	SessionFingerprintPolicy string `mapstructure:"SESSION_FINGERPRINT_POLICY"`
+	SessionMaxPerUser        int    `mapstructure:"SESSION_MAX_PER_USER"`
+
+	AuthCookieMode     string `mapstructure:"AUTH_COOKIE_MODE"`
+	AuthCookieDomain   string `mapstructure:"AUTH_COOKIE_DOMAIN"`
+	AuthCookieSecure   bool   `mapstructure:"AUTH_COOKIE_SECURE"`
+	AuthCookieSameSite string `mapstructure:"AUTH_COOKIE_SAME_SITE"`
+
+	EmailVerificationLifeSpan int    `mapstructure:"EMAIL_VERIFICATION_LIFE_SPAN"`
+	EmailVerificationUrl      string `mapstructure:"EMAIL_VERIFICATION_URL"`
+
+	AuthProviders      string `mapstructure:"AUTH_PROVIDERS"`
+	LdapUrl            string `mapstructure:"LDAP_URL"`
+	LdapStartTls       bool   `mapstructure:"LDAP_START_TLS"`
+	LdapInsecure       bool   `mapstructure:"LDAP_INSECURE"`
+	LdapBindDN         string `mapstructure:"LDAP_BIND_DN"`
+	LdapBindPassword   string `mapstructure:"LDAP_BIND_PASSWORD"`
+	LdapBaseDN         string `mapstructure:"LDAP_BASE_DN"`
+	LdapUserFilter     string `mapstructure:"LDAP_USER_FILTER"`
+	LdapIDAttribute    string `mapstructure:"LDAP_ID_ATTRIBUTE"`
+	LdapEmailAttribute string `mapstructure:"LDAP_EMAIL_ATTRIBUTE"`
+	LdapAutoProvision  bool   `mapstructure:"LDAP_AUTO_PROVISION"`
+	LdapTimeout        int    `mapstructure:"LDAP_TIMEOUT"`
+
+	IntrospectionClients string `mapstructure:"INTROSPECTION_CLIENTS"`
+
+	RegistrationMode   string `mapstructure:"REGISTRATION_MODE"`
+	InviteCodeLifeSpan int    `mapstructure:"INVITE_CODE_LIFE_SPAN"`
+	AdminUsername      string `mapstructure:"ADMIN_USERNAME"`
+	AdminPassword      string `mapstructure:"ADMIN_PASSWORD"`
 }
 
 var globalConfig *Config
@@ -29,6 +132,7 @@ func loadConfig(path string) (config *Config, err error) {
 		viper.SetConfigName("prod")
 	}
 	viper.SetConfigType("env")
+	setDefaults()
 
 	viper.AutomaticEnv()
 
@@ -43,6 +147,96 @@ func loadConfig(path string) (config *Config, err error) {
 	return
 }
 
+// Defaults for optional settings. These match the limits the service had
+// before they were configurable.
+func setDefaults() {
+	viper.SetDefault("TITLE_MAX_LENGTH", 40)
+	viper.SetDefault("DESCRIPTION_MAX_LENGTH", 150)
+	viper.SetDefault("USERNAME_MIN_LENGTH", 3)
+	viper.SetDefault("USERNAME_MAX_LENGTH", 20)
+	viper.SetDefault("DISPLAY_NAME_MAX_LENGTH", 50)
+	viper.SetDefault("PASSWORD_MIN_LENGTH", 8)
+	viper.SetDefault("PASSWORD_MAX_LENGTH", 32)
+	viper.SetDefault("PASSWORD_REQUIRE_LETTER", true)
+	viper.SetDefault("PASSWORD_REQUIRE_UPPER", false)
+	viper.SetDefault("PASSWORD_REQUIRE_NUMBER", true)
+	viper.SetDefault("PASSWORD_REQUIRE_SPECIAL", true)
+	viper.SetDefault("PASSWORD_DENY_LIST_FILE", "")
+	viper.SetDefault("PASSWORD_DENY_USERNAME", true)
+	viper.SetDefault("PASSWORD_HISTORY_SIZE", 5)
+	viper.SetDefault("PASSWORD_HASH_ALGORITHM", "argon2id")
+	viper.SetDefault("PASSWORD_ARGON2_MEMORY", 65536)
+	viper.SetDefault("PASSWORD_ARGON2_ITERATIONS", 3)
+	viper.SetDefault("PASSWORD_ARGON2_PARALLELISM", 2)
+	viper.SetDefault("PASSWORD_BCRYPT_COST", 14)
+	viper.SetDefault("REVOCATION_STORE", "memory")
+	viper.SetDefault("TOKEN_GC_INTERVAL", 60)
+	viper.SetDefault("TOKEN_GC_USED_GRACE", 1440)
+	viper.SetDefault("TOKEN_GC_BATCH_SIZE", 1000)
+	viper.SetDefault("TOTP_ISSUER", "go-todo")
+	viper.SetDefault("TOTP_CHALLENGE_LIFE_SPAN", 5)
+	viper.SetDefault("LOGIN_FREE_ATTEMPTS", 3)
+	viper.SetDefault("LOGIN_LOCKOUT_THRESHOLD", 10)
+	viper.SetDefault("LOGIN_IP_FREE_ATTEMPTS", 10)
+	viper.SetDefault("LOGIN_IP_LOCKOUT_THRESHOLD", 50)
+	viper.SetDefault("LOGIN_BACKOFF_BASE", 1)
+	viper.SetDefault("LOGIN_BACKOFF_MAX", 300)
+	viper.SetDefault("LOGIN_LOCKOUT_DURATION", 15)
+	viper.SetDefault("RATE_LIMIT_STORE", "memory")
+	viper.SetDefault("RATE_LIMIT_AUTH_REQUESTS", 20)
+	viper.SetDefault("RATE_LIMIT_AUTH_WINDOW", 60)
+	viper.SetDefault("RATE_LIMIT_LIST_REQUESTS", 300)
+	viper.SetDefault("RATE_LIMIT_LIST_WINDOW", 60)
+	viper.SetDefault("RATE_LIMIT_DEFAULT_REQUESTS", 120)
+	viper.SetDefault("RATE_LIMIT_DEFAULT_WINDOW", 60)
+	viper.SetDefault("MAIL_BACKEND", "none")
+	viper.SetDefault("MAIL_FROM", "go-todo@localhost")
+	viper.SetDefault("MAIL_FILE", "mail.log")
+	viper.SetDefault("SMTP_HOST", "localhost")
+	viper.SetDefault("SMTP_PORT", 587)
+	viper.SetDefault("SMTP_USERNAME", "")
+	viper.SetDefault("SMTP_PASSWORD", "")
+	viper.SetDefault("PASSWORD_RESET_LIFE_SPAN", 30)
+	viper.SetDefault("PASSWORD_RESET_URL", "")
+	viper.SetDefault("OIDC_ENABLED", false)
+	viper.SetDefault("OIDC_ISSUER", "")
+	viper.SetDefault("OIDC_CLIENT_ID", "")
+	viper.SetDefault("OIDC_CLIENT_SECRET", "")
+	viper.SetDefault("OIDC_REDIRECT_URL", "")
+	viper.SetDefault("OIDC_SCOPES", "openid email profile")
+	viper.SetDefault("OIDC_LINK_BY_EMAIL", false)
+	viper.SetDefault("OIDC_AUTO_PROVISION", true)
+	viper.SetDefault("OIDC_STATE_LIFE_SPAN", 10)
+	viper.SetDefault("JWT_SIGNING_ALGORITHM", "HS512")
+	viper.SetDefault("JWT_KEY_DIR", "keys")
+	viper.SetDefault("IMPERSONATION_LIFE_SPAN", 15)
+	viper.SetDefault("SESSION_FINGERPRINT_POLICY", "off")
+	viper.SetDefault("SESSION_MAX_PER_USER", 0)
+	viper.SetDefault("AUTH_COOKIE_MODE", "off")
+	viper.SetDefault("AUTH_COOKIE_DOMAIN", "")
+	viper.SetDefault("AUTH_COOKIE_SECURE", true)
+	viper.SetDefault("AUTH_COOKIE_SAME_SITE", "strict")
+	viper.SetDefault("EMAIL_VERIFICATION_LIFE_SPAN", 1440)
+	viper.SetDefault("EMAIL_VERIFICATION_URL", "")
+	viper.SetDefault("AUTH_PROVIDERS", "local")
+	viper.SetDefault("LDAP_URL", "ldap://localhost:389")
+	viper.SetDefault("LDAP_START_TLS", true)
+	viper.SetDefault("LDAP_INSECURE", false)
+	viper.SetDefault("LDAP_BIND_DN", "")
+	viper.SetDefault("LDAP_BIND_PASSWORD", "")
+	viper.SetDefault("LDAP_BASE_DN", "")
+	viper.SetDefault("LDAP_USER_FILTER", "(uid=%s)")
+	viper.SetDefault("LDAP_ID_ATTRIBUTE", "")
+	viper.SetDefault("LDAP_EMAIL_ATTRIBUTE", "mail")
+	viper.SetDefault("LDAP_AUTO_PROVISION", true)
+	viper.SetDefault("LDAP_TIMEOUT", 5)
+	viper.SetDefault("INTROSPECTION_CLIENTS", "")
+	viper.SetDefault("REGISTRATION_MODE", "open")
+	viper.SetDefault("INVITE_CODE_LIFE_SPAN", 10080)
+	viper.SetDefault("ADMIN_USERNAME", "")
+	viper.SetDefault("ADMIN_PASSWORD", "")
+}
+
 func Get() (config *Config, err error) {
 	if globalConfig == nil {
 		globalConfig, err = loadConfig(".")
@@ -0,0 +1,27 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	db "go-todo/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Runs fn inside a transaction on a connection of its own from the pool. The
+// transaction is committed if fn returns nil and rolled back otherwise.
+func WithTx(ctx context.Context, pool *pgxpool.Pool, queries *db.Queries, fn func(q *db.Queries) error) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(queries.WithTx(tx)); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			return errors.Join(err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,111 @@
+package fingerprint
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// A coarse description of a client. Browsers update their version every few
+// weeks and phones move between networks, so only what stays the same for a
+// device is kept: the browser or HTTP library, the platform and the network.
+type Fingerprint struct {
+	Client   string
+	Platform string
+	// The /16 of an IPv4 or the /48 of an IPv6 address.
+	Network string
+}
+
+type Policy string
+
+const (
+	// Fingerprints are not compared.
+	PolicyOff Policy = "off"
+	// The client and platform must match.
+	PolicyClient Policy = "client"
+	// The client, platform and network must match.
+	PolicyNetwork Policy = "network"
+)
+
+func ParsePolicy(policy string) (Policy, error) {
+	switch p := Policy(policy); p {
+	case PolicyOff, PolicyClient, PolicyNetwork:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown fingerprint policy: %v", policy)
+}
+
+// Checked in order, so more specific tokens come first. Edge and Opera also
+// claim to be Chrome, Chrome claims to be Safari, Android claims to be Linux
+// and iOS claims to be Mac OS X.
+var clients = []struct{ token, name string }{
+	{"Edg/", "edge"},
+	{"OPR/", "opera"},
+	{"Firefox/", "firefox"},
+	{"FxiOS/", "firefox"},
+	{"CriOS/", "chrome"},
+	{"Chrome/", "chrome"},
+	{"Safari/", "safari"},
+}
+
+var platforms = []struct{ token, name string }{
+	{"iPhone", "ios"},
+	{"iPad", "ios"},
+	{"Android", "android"},
+	{"CrOS", "chromeos"},
+	{"Windows", "windows"},
+	{"Mac OS X", "macos"},
+	{"Macintosh", "macos"},
+	{"Linux", "linux"},
+}
+
+func New(userAgent string, ip string) Fingerprint {
+	return Fingerprint{
+		Client:   client(userAgent),
+		Platform: platform(userAgent),
+		Network:  network(ip),
+	}
+}
+
+// Tells whether a request with fingerprint other may continue a session
+// created with f.
+func (f Fingerprint) Matches(other Fingerprint, policy Policy) bool {
+	switch policy {
+	case PolicyClient:
+		return f.Client == other.Client && f.Platform == other.Platform
+	case PolicyNetwork:
+		return f.Client == other.Client && f.Platform == other.Platform && f.Network == other.Network
+	}
+	return true
+}
+
+func client(userAgent string) string {
+	for _, c := range clients {
+		if strings.Contains(userAgent, c.token) {
+			return c.name
+		}
+	}
+	// Other clients, e.g. curl/8.5.0, are named by their first product.
+	product, _, _ := strings.Cut(userAgent, "/")
+	return strings.ToLower(strings.TrimSpace(product))
+}
+
+func platform(userAgent string) string {
+	for _, p := range platforms {
+		if strings.Contains(userAgent, p.token) {
+			return p.name
+		}
+	}
+	return ""
+}
+
+func network(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(16, 32)).String()
+	}
+	return parsed.Mask(net.CIDRMask(48, 128)).String()
+}
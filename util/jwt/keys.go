@@ -0,0 +1,355 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go-todo/util/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	kidTimeFormat    = "20060102T150405Z"
+	keyCheckInterval = time.Minute
+	privateKeySuffix = ".pem"
+	publicKeySuffix  = ".pub.pem"
+	// How long other services may cache /.well-known/jwks.json.
+	JwksMaxAge = 5 * time.Minute
+	// A new key is published once every instance has read it, and signs once
+	// caches of the keys published before have expired.
+	keyActivationDelay = keyCheckInterval + JwksMaxAge
+)
+
+var ErrUnknownKid = errors.New("unknown key id")
+
+// A key access tokens can be verified with. The kid is the file name without
+// the suffix.
+type verificationKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+}
+
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.Signer
+	// When the key may start signing.
+	activeAt time.Time
+}
+
+// Asymmetric keys for access tokens, loaded from PEM files in a directory.
+// Private keys are named <kid>.pem and public keys of other signers
+// <kid>.pub.pem. All keys verify tokens, so a new key can be added without
+// invalidating tokens signed with the old ones. The private key with the
+// greatest kid that is older than keyActivationDelay signs new tokens, so
+// services that cached the keys before it was added don't reject them. The
+// directory is checked for changes at most once a minute, and right away
+// when a token has an unknown kid.
+type keySet struct {
+	dir string
+
+	mu sync.Mutex
+	// Sorted by kid, oldest first.
+	signers   []*signingKey
+	verify    map[string]verificationKey
+	state     string
+	lastCheck time.Time
+}
+
+// Nil when access tokens are signed with JWT_ACCESS_SECRET.
+var keys *keySet
+
+// Loads the signing keys when JWT_SIGNING_ALGORITHM is asymmetric. Should be
+// called once at startup.
+func ConfigureKeys(cfg *config.Config) error {
+	switch cfg.JwtSigningAlgorithm {
+	case "HS512":
+		keys = nil
+		return nil
+	case "EdDSA", "RS256":
+		ks := &keySet{dir: cfg.JwtKeyDir}
+		if err := ks.reload(); err != nil {
+			return err
+		}
+		if len(ks.signers) == 0 {
+			return fmt.Errorf("no private key in %v, create one with the keys rotate command", cfg.JwtKeyDir)
+		}
+		keys = ks
+		return nil
+	}
+	return fmt.Errorf("unknown jwt signing algorithm: %v", cfg.JwtSigningAlgorithm)
+}
+
+func (ks *keySet) getSigningKey() (*signingKey, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if time.Since(ks.lastCheck) >= keyCheckInterval {
+		if err := ks.reloadLocked(); err != nil {
+			return nil, err
+		}
+	}
+	if len(ks.signers) == 0 {
+		return nil, fmt.Errorf("no private key in %v", ks.dir)
+	}
+	now := time.Now()
+	for i := len(ks.signers) - 1; i >= 0; i-- {
+		if !ks.signers[i].activeAt.After(now) {
+			return ks.signers[i], nil
+		}
+	}
+	// Nothing can have cached keys of a new key directory.
+	return ks.signers[0], nil
+}
+
+func (ks *keySet) getVerificationKey(kid string) (verificationKey, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if key, ok := ks.verify[kid]; ok {
+		return key, nil
+	}
+	// The key may have been added after the last check. Checking is limited so
+	// tokens with made up kids don't cause a directory read each.
+	if time.Since(ks.lastCheck) >= time.Second {
+		if err := ks.reloadLocked(); err != nil {
+			return verificationKey{}, err
+		}
+		if key, ok := ks.verify[kid]; ok {
+			return key, nil
+		}
+	}
+	return verificationKey{}, fmt.Errorf("%w: %v", ErrUnknownKid, kid)
+}
+
+func (ks *keySet) reload() error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	return ks.reloadLocked()
+}
+
+// Reads the keys again if files were added, removed or changed. Must be called
+// with the lock held.
+func (ks *keySet) reloadLocked() error {
+	ks.lastCheck = time.Now()
+	entries, err := os.ReadDir(ks.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read key directory: %w", err)
+	}
+
+	var state strings.Builder
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), privateKeySuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("failed to read key file: %w", err)
+		}
+		fmt.Fprintf(&state, "%v:%v:%v;", entry.Name(), info.Size(), info.ModTime().UnixNano())
+		names = append(names, entry.Name())
+	}
+	if state.String() == ks.state && ks.verify != nil {
+		return nil
+	}
+
+	slices.Sort(names)
+	verify := map[string]verificationKey{}
+	var signers []*signingKey
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(ks.dir, name))
+		if err != nil {
+			return fmt.Errorf("failed to read key file: %w", err)
+		}
+		isPublic := strings.HasSuffix(name, publicKeySuffix)
+		kid := strings.TrimSuffix(name, publicKeySuffix)
+		if !isPublic {
+			kid = strings.TrimSuffix(name, privateKeySuffix)
+		}
+
+		key, err := parsePem(data, isPublic)
+		if err != nil {
+			return fmt.Errorf("failed to parse key %v: %w", name, err)
+		}
+		method, err := signingMethod(key)
+		if err != nil {
+			return fmt.Errorf("failed to parse key %v: %w", name, err)
+		}
+		if signer, ok := key.(crypto.Signer); ok {
+			verify[kid] = verificationKey{kid: kid, method: method, key: signer.Public()}
+			signers = append(signers, &signingKey{
+				kid:      kid,
+				method:   method,
+				key:      signer,
+				activeAt: kidTime(kid).Add(keyActivationDelay),
+			})
+		} else {
+			verify[kid] = verificationKey{kid: kid, method: method, key: key}
+		}
+	}
+
+	ks.verify = verify
+	ks.signers = signers
+	ks.state = state.String()
+	return nil
+}
+
+// Returns when the key was created, or the zero time for kids that weren't
+// made by GenerateKeyFile.
+func kidTime(kid string) time.Time {
+	created, err := time.Parse(kidTimeFormat, strings.SplitN(kid, "-", 2)[0])
+	if err != nil {
+		return time.Time{}
+	}
+	return created
+}
+
+func parsePem(data []byte, isPublic bool) (any, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no pem block")
+	}
+	if isPublic {
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}
+
+func signingMethod(key any) (jwt.SigningMethod, error) {
+	switch key.(type) {
+	case ed25519.PrivateKey, ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	case *rsa.PrivateKey, *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T", key)
+}
+
+// Returns the public keys in JWK format for /.well-known/jwks.json.
+func PublicJwks() ([]map[string]string, error) {
+	if keys == nil {
+		return []map[string]string{}, nil
+	}
+	if _, err := keys.getSigningKey(); err != nil {
+		return nil, err
+	}
+
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+	kids := make([]string, 0, len(keys.verify))
+	for kid := range keys.verify {
+		kids = append(kids, kid)
+	}
+	slices.Sort(kids)
+
+	jwks := make([]map[string]string, 0, len(kids))
+	encode := base64.RawURLEncoding.EncodeToString
+	for _, kid := range kids {
+		key := keys.verify[kid]
+		jwk := map[string]string{
+			"kid": kid,
+			"use": "sig",
+			"alg": key.method.Alg(),
+		}
+		switch publicKey := key.key.(type) {
+		case ed25519.PublicKey:
+			jwk["kty"] = "OKP"
+			jwk["crv"] = "Ed25519"
+			jwk["x"] = encode(publicKey)
+		case *rsa.PublicKey:
+			jwk["kty"] = "RSA"
+			jwk["n"] = encode(publicKey.N.Bytes())
+			jwk["e"] = encode(big.NewInt(int64(publicKey.E)).Bytes())
+		}
+		jwks = append(jwks, jwk)
+	}
+	return jwks, nil
+}
+
+// Writes a new private key for the algorithm to the directory. It becomes the
+// signing key after keyActivationDelay as its kid is the greatest. Returns the
+// kid.
+func GenerateKeyFile(dir string, algorithm string) (string, error) {
+	var key crypto.Signer
+	var err error
+	switch algorithm {
+	case "EdDSA":
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	case "RS256":
+		key, err = rsa.GenerateKey(rand.Reader, 3072)
+	default:
+		return "", fmt.Errorf("keys can't be generated for algorithm %v", algorithm)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode key: %w", err)
+	}
+	suffix := make([]byte, 2)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate kid: %w", err)
+	}
+	kid := time.Now().UTC().Format(kidTimeFormat) + "-" + hex.EncodeToString(suffix)
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create key directory: %w", err)
+	}
+	path := filepath.Join(dir, kid+privateKeySuffix)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write key: %w", err)
+	}
+	return kid, nil
+}
+
+// Deletes the private keys that can't have signed a token that is still
+// valid: keys replaced by a newer key more than maxTokenAge ago. Returns the
+// kids of the deleted keys. Public keys are left alone.
+func PruneKeyFiles(dir string, maxTokenAge time.Duration) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key directory: %w", err)
+	}
+	var kids []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, privateKeySuffix) || strings.HasSuffix(name, publicKeySuffix) {
+			continue
+		}
+		kids = append(kids, strings.TrimSuffix(name, privateKeySuffix))
+	}
+	slices.Sort(kids)
+
+	var pruned []string
+	for i := 0; i < len(kids)-1; i++ {
+		// A key signs until the next one is active.
+		created := kidTime(kids[i+1])
+		if created.IsZero() || time.Since(created.Add(keyActivationDelay)) <= maxTokenAge {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, kids[i]+privateKeySuffix)); err != nil {
+			return pruned, fmt.Errorf("failed to delete key %v: %w", kids[i], err)
+		}
+		pruned = append(pruned, kids[i])
+	}
+	return pruned, nil
+}
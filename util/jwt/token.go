@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"crypto/hmac"
+	"crypto/sha512"
 	"errors"
 	"fmt"
 	"go-todo/util/config"
@@ -16,9 +18,17 @@ type GtClaims struct {
 	IsAdmin  bool   `json:"is_admin"`
 	Username string `json:"username"`
 	Family   string `json:"family"`
+	// Set when an admin impersonates the subject (RFC 8693).
+	Act *ActClaim `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// The admin acting as the subject of an impersonation token.
+type ActClaim struct {
+	Subject  string `json:"sub"`
+	Username string `json:"username"`
+}
+
 type JwtErrorReason int
 
 const (
@@ -61,7 +71,48 @@ func NewJwtDecodeError(claims *GtClaims, reason JwtErrorReason, err error) *JwtD
 	}
 }
 
-func generateJwt(username string, userID string, isAdmin bool, isRefreshToken bool, family string) (string, *GtClaims, error) {
+type tokenKind int
+
+const (
+	tokenKindAccess tokenKind = iota
+	tokenKindRefresh
+	tokenKindChallenge
+	tokenKindImpersonation
+)
+
+// Impersonation tokens are access tokens with a shorter life span.
+func (k tokenKind) isAccess() bool {
+	return k == tokenKindAccess || k == tokenKindImpersonation
+}
+
+// Returns the secret the kind of token is signed with. Challenge tokens use a
+// key derived from the access secret so they can't be used as access tokens.
+func (k tokenKind) secret(config *config.Config) []byte {
+	switch k {
+	case tokenKindRefresh:
+		return []byte(config.JwtRefreshSecret)
+	case tokenKindChallenge:
+		mac := hmac.New(sha512.New, []byte(config.JwtAccessSecret))
+		mac.Write([]byte("go-todo:2fa-challenge"))
+		return mac.Sum(nil)
+	}
+	return []byte(config.JwtAccessSecret)
+}
+
+// Returns the life span of the kind of token in minutes.
+func (k tokenKind) lifeSpan(config *config.Config) int {
+	switch k {
+	case tokenKindRefresh:
+		return config.RefreshTokenLifeSpan
+	case tokenKindChallenge:
+		return config.TotpChallengeLifeSpan
+	case tokenKindImpersonation:
+		return config.ImpersonationLifeSpan
+	}
+	return config.AccessTokenLifeSpan
+}
+
+func generateJwt(username string, userID string, isAdmin bool, kind tokenKind, family string, act *ActClaim) (string, *GtClaims, error) {
 	generateError := func(err error) error {
 		return fmt.Errorf("GenerateJwtError: %w", err)
 	}
@@ -71,22 +122,23 @@ func generateJwt(username string, userID string, isAdmin bool, isRefreshToken bo
 		return "", nil, generateError(err)
 	}
 
-	authLifeSpan := config.AccessTokenLifeSpan
-	if isRefreshToken {
-		authLifeSpan = config.RefreshTokenLifeSpan
-	}
-	lifeSpanDuration := time.Minute * time.Duration(authLifeSpan)
+	lifeSpanDuration := time.Minute * time.Duration(kind.lifeSpan(config))
 	timeNow := time.Now().UTC()
 	expiry := jwt.NewNumericDate(timeNow.Add(lifeSpanDuration))
-	if family == "" && isRefreshToken {
+	if family == "" && kind == tokenKindRefresh {
 		family = uuid.New().String()
-	} else if !isRefreshToken {
+	} else if kind == tokenKindChallenge {
+		family = "challenge"
+	} else if kind == tokenKindImpersonation {
+		family = "impersonation"
+	} else if family == "" {
 		family = "access"
 	}
 	claims := GtClaims{
 		isAdmin,
 		username,
 		family,
+		act,
 		jwt.RegisteredClaims{
 			ID:        uuid.New().String(),
 			Subject:   userID,
@@ -95,43 +147,63 @@ func generateJwt(username string, userID string, isAdmin bool, isRefreshToken bo
 			IssuedAt:  jwt.NewNumericDate(timeNow),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
-
-	secret := config.JwtAccessSecret
-	if isRefreshToken {
-		secret = config.JwtRefreshSecret
+	var encodedToken string
+	if kind.isAccess() && keys != nil {
+		key, err := keys.getSigningKey()
+		if err != nil {
+			return "", nil, generateError(err)
+		}
+		token := jwt.NewWithClaims(key.method, claims)
+		token.Header["kid"] = key.kid
+		encodedToken, err = token.SignedString(key.key)
+	} else {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
+		encodedToken, err = token.SignedString(kind.secret(config))
 	}
-	encodedToken, err := token.SignedString([]byte(secret))
 	if err != nil {
 		return "", nil, generateError(err)
 	}
 	return encodedToken, &claims, nil
 }
 
-func GenerateAccessJwt(username string, userID string, isAdmin bool) (string, *GtClaims, error) {
-	return generateJwt(username, userID, isAdmin, false, "")
+// Family should be the family of the refresh token issued with the access
+// token so the access token can be tied to the session.
+func GenerateAccessJwt(username string, userID string, isAdmin bool, family string) (string, *GtClaims, error) {
+	return generateJwt(username, userID, isAdmin, tokenKindAccess, family, nil)
 }
 
 func GenerateRefreshJwt(username string, userID string, isAdmin bool, tokenFamily string) (string, *GtClaims, error) {
-	return generateJwt(username, userID, isAdmin, true, tokenFamily)
+	return generateJwt(username, userID, isAdmin, tokenKindRefresh, tokenFamily, nil)
+}
+
+// Challenge tokens prove that the password was correct when a second factor
+// is still needed. They can't be used for anything else.
+func GenerateChallengeJwt(username string, userID string, isAdmin bool) (string, *GtClaims, error) {
+	return generateJwt(username, userID, isAdmin, tokenKindChallenge, "", nil)
+}
+
+// Impersonation tokens let an admin act as another user. They are access
+// tokens naming the admin in the act claim and come without a refresh token.
+func GenerateImpersonationJwt(username string, userID string, isAdmin bool, act ActClaim) (string, *GtClaims, error) {
+	return generateJwt(username, userID, isAdmin, tokenKindImpersonation, "", &act)
 }
 
-// Takes a jwt as a string and boolean isRefreshToken telling should it be
-// decoded with refresh secret. If all goes well, returns claims and if not,
+// Takes a jwt as a string and the kind of token telling which secret it
+// should be decoded with. If all goes well, returns claims and if not,
 // returns JwtValidationError or normal error.
-func decodeJwt(tokenString string, isRefreshToken bool) (*GtClaims, error) {
+func decodeJwt(tokenString string, kind tokenKind) (*GtClaims, error) {
 	config, err := config.Get()
 	if err != nil {
 		return nil, err
 	}
 
-	secret := config.JwtAccessSecret
-	if isRefreshToken {
-		secret = config.JwtRefreshSecret
+	// Access tokens are signed with the key set when there is one, which makes
+	// refresh and challenge tokens fail as they are signed with HS512.
+	keyFunc, methods := secretKeyFunc(kind.secret(config))
+	if kind == tokenKindAccess && keys != nil {
+		keyFunc, methods = keySetKeyFunc(keys)
 	}
-	decodedToken, err := jwt.ParseWithClaims(tokenString, &GtClaims{}, func(token *jwt.Token) (any, error) {
-		return []byte(secret), nil
-	})
+	decodedToken, err := jwt.ParseWithClaims(tokenString, &GtClaims{}, keyFunc, jwt.WithValidMethods(methods))
 	if err != nil {
 		if decodedToken == nil {
 			reason := JwtErrorReasonUnhandled
@@ -146,7 +218,7 @@ func decodeJwt(tokenString string, isRefreshToken bool) (*GtClaims, error) {
 			switch {
 			case errors.Is(err, jwt.ErrTokenExpired):
 				reason = JwtErrorReasonExpired
-			case errors.Is(err, jwt.ErrSignatureInvalid):
+			case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, ErrUnknownKid):
 				reason = JwtErrorReasonInvalidSignature
 			default:
 				return nil, NewJwtDecodeError(claims, reason, err)
@@ -161,12 +233,41 @@ func decodeJwt(tokenString string, isRefreshToken bool) (*GtClaims, error) {
 	}
 }
 
+func secretKeyFunc(secret []byte) (jwt.Keyfunc, []string) {
+	return func(token *jwt.Token) (any, error) {
+		return secret, nil
+	}, []string{jwt.SigningMethodHS512.Alg()}
+}
+
+// Picks the verification key by the kid header. The algorithm has to match
+// the key so a token can't pick a weaker one.
+func keySetKeyFunc(ks *keySet) (jwt.Keyfunc, []string) {
+	return func(token *jwt.Token) (any, error) {
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: missing kid", ErrUnknownKid)
+		}
+		key, err := ks.getVerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("algorithm %v doesn't match key %v", token.Method.Alg(), kid)
+		}
+		return key.key, nil
+	}, []string{jwt.SigningMethodEdDSA.Alg(), jwt.SigningMethodRS256.Alg()}
+}
+
 func DecodeAccessToken(tokenString string) (*GtClaims, error) {
-	return decodeJwt(tokenString, false)
+	return decodeJwt(tokenString, tokenKindAccess)
 }
 
 func DecodeRefreshToken(tokenString string) (*GtClaims, error) {
-	return decodeJwt(tokenString, true)
+	return decodeJwt(tokenString, tokenKindRefresh)
+}
+
+func DecodeChallengeToken(tokenString string) (*GtClaims, error) {
+	return decodeJwt(tokenString, tokenKindChallenge)
 }
 
 func getTokenFromHeader(c *gin.Context) string {
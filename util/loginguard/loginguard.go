@@ -0,0 +1,198 @@
+package loginguard
+
+import (
+	"sync"
+	"time"
+
+	"go-todo/util/config"
+)
+
+const sweepInterval = time.Minute
+
+// How many failures are tolerated for one kind of key before backing off and
+// locking out.
+type Limit struct {
+	FreeAttempts     int
+	LockoutThreshold int
+}
+
+type Policy struct {
+	User            Limit
+	Ip              Limit
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	LockoutDuration time.Duration
+}
+
+type counter struct {
+	failures     int
+	lastFailure  time.Time
+	blockedUntil time.Time
+}
+
+// Counts failed logins per username and per IP. Each failure past the free
+// attempts blocks further attempts for an exponentially growing delay, and
+// reaching the lockout threshold blocks them for the whole lockout duration.
+// Counters are kept in memory, so each instance counts on its own.
+type Guard struct {
+	mu        sync.Mutex
+	policy    Policy
+	users     map[string]*counter
+	ips       map[string]*counter
+	lastSweep time.Time
+}
+
+func New(policy Policy) *Guard {
+	return &Guard{
+		policy:    policy,
+		users:     map[string]*counter{},
+		ips:       map[string]*counter{},
+		lastSweep: time.Now(),
+	}
+}
+
+func PolicyFromConfig(cfg *config.Config) Policy {
+	return Policy{
+		User: Limit{
+			FreeAttempts:     cfg.LoginFreeAttempts,
+			LockoutThreshold: cfg.LoginLockoutThreshold,
+		},
+		Ip: Limit{
+			FreeAttempts:     cfg.LoginIpFreeAttempts,
+			LockoutThreshold: cfg.LoginIpLockoutThreshold,
+		},
+		BaseDelay:       time.Duration(cfg.LoginBackoffBase) * time.Second,
+		MaxDelay:        time.Duration(cfg.LoginBackoffMax) * time.Second,
+		LockoutDuration: time.Duration(cfg.LoginLockoutDuration) * time.Minute,
+	}
+}
+
+// Returns how long the username or the IP is still blocked. Zero means the
+// login may be attempted.
+func (g *Guard) Check(username, ip string) time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now()
+	wait := time.Duration(0)
+	if c, ok := g.users[username]; ok {
+		wait = max(wait, c.blockedUntil.Sub(now))
+	}
+	if c, ok := g.ips[ip]; ok {
+		wait = max(wait, c.blockedUntil.Sub(now))
+	}
+	return wait
+}
+
+// Records a failed login. Returns how long the next attempt is blocked and
+// whether the username just got locked out.
+func (g *Guard) Fail(username, ip string) (retryAfter time.Duration, lockedOut bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.sweep()
+	now := time.Now()
+	userWait, lockedOut := g.fail(g.users, username, g.policy.User, now)
+	ipWait, _ := g.fail(g.ips, ip, g.policy.Ip, now)
+	return max(userWait, ipWait), lockedOut
+}
+
+// Must be called with the lock held.
+func (g *Guard) fail(counters map[string]*counter, key string, limit Limit, now time.Time) (time.Duration, bool) {
+	c, ok := counters[key]
+	if !ok || g.forgettable(c, now) {
+		c = &counter{}
+		counters[key] = c
+	}
+	c.failures++
+	c.lastFailure = now
+
+	lockedOut := false
+	switch {
+	case limit.LockoutThreshold > 0 && c.failures >= limit.LockoutThreshold:
+		lockedOut = c.failures == limit.LockoutThreshold
+		c.blockedUntil = now.Add(g.policy.LockoutDuration)
+	case c.failures > limit.FreeAttempts:
+		c.blockedUntil = now.Add(g.backoff(c.failures - limit.FreeAttempts))
+	}
+	return max(c.blockedUntil.Sub(now), 0), lockedOut
+}
+
+// Delay after the nth failure past the free attempts: BaseDelay doubled for
+// each further failure, capped at MaxDelay.
+func (g *Guard) backoff(n int) time.Duration {
+	delay := g.policy.BaseDelay
+	for i := 1; i < n && delay < g.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, g.policy.MaxDelay)
+}
+
+// Clears the failures of the username after a successful login. The IP keeps
+// its failures so logging in to one account doesn't reset guessing on others.
+func (g *Guard) Succeed(username string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.users, username)
+}
+
+// Clears the failures and any lockout of the username. Returns false if there
+// was nothing to clear.
+func (g *Guard) Unlock(username string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, ok := g.users[username]
+	delete(g.users, username)
+	return ok
+}
+
+// A counter is forgotten once it's no longer blocking and no failure has been
+// recorded for the lockout duration.
+func (g *Guard) forgettable(c *counter, now time.Time) bool {
+	return !c.blockedUntil.After(now) && now.Sub(c.lastFailure) > g.policy.LockoutDuration
+}
+
+// Removes forgettable counters. Runs at most once per sweepInterval. Must be
+// called with the lock held.
+func (g *Guard) sweep() {
+	now := time.Now()
+	if now.Sub(g.lastSweep) < sweepInterval {
+		return
+	}
+	g.lastSweep = now
+	for _, counters := range []map[string]*counter{g.users, g.ips} {
+		for key, c := range counters {
+			if g.forgettable(c, now) {
+				delete(counters, key)
+			}
+		}
+	}
+}
+
+var guard = New(Policy{
+	User:            Limit{FreeAttempts: 3, LockoutThreshold: 10},
+	Ip:              Limit{FreeAttempts: 10, LockoutThreshold: 50},
+	BaseDelay:       time.Second,
+	MaxDelay:        5 * time.Minute,
+	LockoutDuration: 15 * time.Minute,
+})
+
+// Replaces the guard used by the package level functions with one using the
+// policy from the config. Should be called once at startup.
+func Configure(cfg *config.Config) {
+	guard = New(PolicyFromConfig(cfg))
+}
+
+func Check(username, ip string) time.Duration {
+	return guard.Check(username, ip)
+}
+
+func Fail(username, ip string) (time.Duration, bool) {
+	return guard.Fail(username, ip)
+}
+
+func Succeed(username string) {
+	guard.Succeed(username)
+}
+
+func Unlock(username string) bool {
+	return guard.Unlock(username)
+}
@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Logs mail instead of sending it. For development only, as the log then
+// contains whatever secrets the mail does.
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	slog.Info(
+		"mail",
+		slog.String("to", msg.To),
+		slog.String("subject", msg.Subject),
+		slog.String("body", msg.Body),
+	)
+	return nil
+}
+
+// Appends mail to a file instead of sending it. For development only.
+type FileMailer struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileMailer(path string) *FileMailer {
+	return &FileMailer{path: path}
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open mail file: %w", err)
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(
+		file,
+		"Date: %v\nTo: %v\nSubject: %v\n\n%v\n\n",
+		time.Now().Format(time.RFC1123Z),
+		msg.To,
+		msg.Subject,
+		msg.Body,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+	return nil
+}
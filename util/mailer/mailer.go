@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go-todo/util/config"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sends plain text mail.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Returns the mailer selected with MAIL_BACKEND, or nil if it's "none". The
+// log and file mailers keep the tokens of the mail readable, so they are for
+// development only.
+func New(cfg *config.Config) (Mailer, error) {
+	switch cfg.MailBackend {
+	case "none":
+		return nil, nil
+	case "log":
+		slog.Warn("Mail is written to the log, which is for development only.")
+		return NewLogMailer(), nil
+	case "file":
+		slog.Warn("Mail is written to a file, which is for development only.", slog.String("file", cfg.MailFile))
+		return NewFileMailer(cfg.MailFile), nil
+	case "smtp":
+		return NewSmtpMailer(
+			cfg.SmtpHost,
+			cfg.SmtpPort,
+			cfg.SmtpUsername,
+			cfg.SmtpPassword,
+			cfg.MailFrom,
+		), nil
+	}
+	return nil, fmt.Errorf("unknown mail backend: %v", cfg.MailBackend)
+}
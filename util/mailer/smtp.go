@@ -0,0 +1,50 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SmtpMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// Without a username mail is sent without authentication.
+func NewSmtpMailer(host string, port int, username, password, from string) *SmtpMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SmtpMailer{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		auth: auth,
+		from: from,
+	}
+}
+
+func (m *SmtpMailer) Send(ctx context.Context, msg Message) error {
+	// Header injection through the recipient or the subject.
+	if strings.ContainsAny(msg.To+msg.Subject, "\r\n") {
+		return fmt.Errorf("invalid mail header")
+	}
+	body := strings.ReplaceAll(msg.Body, "\n", "\r\n")
+	data := fmt.Sprintf(
+		"From: %v\r\nTo: %v\r\nSubject: %v\r\nDate: %v\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%v\r\n",
+		m.from,
+		msg.To,
+		msg.Subject,
+		time.Now().Format(time.RFC1123Z),
+		body,
+	)
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, []byte(data)); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
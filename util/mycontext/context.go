@@ -3,11 +3,15 @@ package mycontext
 import (
 	"errors"
 	"fmt"
+	"time"
 
+	db "go-todo/db/sqlc"
 	"go-todo/gterrors"
+	"go-todo/util/jwt"
 	"go-todo/util/txtutil"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 func getBooleanKey(key string, c *gin.Context) (bool, bool) {
@@ -35,6 +39,46 @@ func GetTokenVariables(ctx *gin.Context) (userID string, username string, isAdmi
 	return userID, username, isAdmin, nil
 }
 
+// Returns the id and expiry of the access token the request was made with.
+func GetTokenId(ctx *gin.Context) (jti string, expiresAt time.Time, err error) {
+	jti = ctx.GetString("x-token-jti")
+	expiresAt = ctx.GetTime("x-token-expires-at")
+	if jti == "" || expiresAt.IsZero() {
+		return "", time.Time{}, errors.New("failed to get token id")
+	}
+	return jti, expiresAt, nil
+}
+
+// Returns the refresh token family (session) the access token was issued
+// for. Access tokens issued before sessions were tracked have the family
+// "access".
+func GetTokenFamily(ctx *gin.Context) string {
+	return ctx.GetString("x-token-family")
+}
+
+// Tells whether the request is made with a personal access token.
+func IsPersonalToken(ctx *gin.Context) bool {
+	isPat, _ := getBooleanKey("x-token-is-pat", ctx)
+	return isPat
+}
+
+// Tells whether the user can act as an admin in this request. Admins don't
+// get admin rights with personal access tokens, whatever their scopes are.
+func HasAdminRights(user *db.User, ctx *gin.Context) bool {
+	return user.IsAdmin && !IsPersonalToken(ctx)
+}
+
+// Returns the admin impersonating the user, nil if the request isn't made
+// with an impersonation token.
+func GetTokenAct(ctx *gin.Context) *jwt.ActClaim {
+	if act, ok := ctx.Get("x-token-act"); ok {
+		if act, ok := act.(*jwt.ActClaim); ok {
+			return act
+		}
+	}
+	return nil
+}
+
 func CtxAddGtInternalError(message, file string, line int, err error, c *gin.Context) {
 	errToAdd := err
 	if message != "" {
@@ -58,3 +102,12 @@ func ShouldBindBodyWithJSON(payload any, c *gin.Context) bool {
 	}
 	return true
 }
+
+// Binds a form encoded body, for endpoints that follow specs using forms.
+func ShouldBindForm(payload any, c *gin.Context) bool {
+	if err := c.ShouldBindWith(payload, binding.Form); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return false
+	}
+	return true
+}
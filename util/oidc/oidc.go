@@ -0,0 +1,276 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go-todo/util/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrIdTokenInvalid = errors.New("id token is invalid")
+
+type Settings struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+func SettingsFromConfig(cfg *config.Config) Settings {
+	return Settings{
+		Issuer:       strings.TrimSuffix(cfg.OidcIssuer, "/"),
+		ClientID:     cfg.OidcClientID,
+		ClientSecret: cfg.OidcClientSecret,
+		RedirectURL:  cfg.OidcRedirectUrl,
+		Scopes:       strings.Fields(cfg.OidcScopes),
+	}
+}
+
+// The parts of the discovery document that are used.
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+// Claims of a verified ID token.
+type Claims struct {
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name"`
+	jwt.RegisteredClaims
+}
+
+// Client of an OpenID Connect provider for the authorization code flow with
+// PKCE. The discovery document and the signing keys are fetched from the
+// issuer on first use, so the provider doesn't need to be up at startup.
+type Provider struct {
+	settings   Settings
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	discovery *discovery
+	keys      map[string]any
+}
+
+// A nil client uses a client with a 10 second timeout.
+func NewProvider(settings Settings, httpClient *http.Client) *Provider {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{settings: settings, httpClient: httpClient}
+}
+
+func (p *Provider) Issuer() string {
+	return p.settings.Issuer
+}
+
+// Returns a random value for the state, the nonce or the PKCE verifier.
+func RandomString() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate random string: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// Returns the S256 PKCE challenge for the verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Returns the URL the user is sent to for logging in at the provider.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.settings.ClientID},
+		"redirect_uri":          {p.settings.RedirectURL},
+		"scope":                 {strings.Join(p.settings.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {CodeChallenge(codeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return d.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchanges the authorization code for tokens and returns the claims of the
+// verified ID token. The nonce must match the one sent with AuthCodeURL.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Claims, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.settings.RedirectURL},
+		"client_id":     {p.settings.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	if p.settings.ClientSecret != "" {
+		form.Set("client_secret", p.settings.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var tokenResponse struct {
+		IdToken string `json:"id_token"`
+	}
+	if err := p.doJSON(req, &tokenResponse); err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	if tokenResponse.IdToken == "" {
+		return nil, fmt.Errorf("%w: token response has no id token", ErrIdTokenInvalid)
+	}
+
+	claims, err := p.verify(ctx, tokenResponse.IdToken, d.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce doesn't match", ErrIdTokenInvalid)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: subject is missing", ErrIdTokenInvalid)
+	}
+	return claims, nil
+}
+
+func (p *Provider) verify(ctx context.Context, idToken string, issuer string) (*Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(
+		idToken,
+		&claims,
+		func(token *jwt.Token) (any, error) {
+			kid, _ := token.Header["kid"].(string)
+			return p.getKey(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(p.settings.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIdTokenInvalid, err)
+	}
+	return &claims, nil
+}
+
+func (p *Provider) getDiscovery(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.settings.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery request: %w", err)
+	}
+	var d discovery
+	if err := p.doJSON(req, &d); err != nil {
+		return nil, fmt.Errorf("failed to get discovery document: %w", err)
+	}
+	if strings.TrimSuffix(d.Issuer, "/") != p.settings.Issuer {
+		return nil, fmt.Errorf("discovery document is for issuer %v, expected %v", d.Issuer, p.settings.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JwksUri == "" {
+		return nil, errors.New("discovery document is missing endpoints")
+	}
+	p.discovery = &d
+	return p.discovery, nil
+}
+
+// Returns the verification key with the kid. The keys are fetched again when
+// the kid is unknown, as the provider may have rotated its keys.
+func (p *Provider) getKey(ctx context.Context, kid string) (any, error) {
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.JwksUri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jwks request: %w", err)
+	}
+	var set jwkSet
+	if err := p.doJSON(req, &set); err != nil {
+		return nil, fmt.Errorf("failed to get jwks: %w", err)
+	}
+	keys, err := set.publicKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	// Providers with a single key don't always set a kid.
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	key, ok = keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %v", kid)
+	}
+	return key, nil
+}
+
+func (p *Provider) doJSON(req *http.Request, target any) error {
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %v: %s", res.StatusCode, body)
+	}
+	return json.Unmarshal(body, target)
+}
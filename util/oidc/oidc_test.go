@@ -0,0 +1,111 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"go-todo/util/oidc"
+	"go-todo/util/oidc/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Runs a login up to the code exchange. The verifier and nonce given to
+// Exchange can differ from the ones the login was started with.
+func login(t *testing.T, m *oidctest.Issuer, p *oidc.Provider, exchangeVerifier, exchangeNonce *string) (*oidc.Claims, error) {
+	t.Helper()
+	verifier, err := oidc.RandomString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := p.AuthCodeURL(context.Background(), "state", nonce, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := m.Authorize(t, authURL)
+	if exchangeVerifier != nil {
+		verifier = *exchangeVerifier
+	}
+	if exchangeNonce != nil {
+		nonce = *exchangeNonce
+	}
+	return p.Exchange(context.Background(), code, verifier, nonce)
+}
+
+func TestExchange(t *testing.T) {
+	m := oidctest.NewIssuer(t)
+	claims, err := login(t, m, m.Provider(), nil, nil)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if claims.Subject != "user-1" || claims.Email != "user@example.com" || !claims.EmailVerified {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	m := oidctest.NewIssuer(t)
+	wrong := "not-the-verifier"
+	if _, err := login(t, m, m.Provider(), &wrong, nil); err == nil {
+		t.Fatal("Exchange succeeded with a wrong PKCE verifier")
+	}
+}
+
+func TestExchangeRejectsWrongNonce(t *testing.T) {
+	m := oidctest.NewIssuer(t)
+	wrong := "not-the-nonce"
+	_, err := login(t, m, m.Provider(), nil, &wrong)
+	if !errors.Is(err, oidc.ErrIdTokenInvalid) {
+		t.Fatalf("err = %v, want ErrIdTokenInvalid", err)
+	}
+}
+
+func TestExchangeRejectsInvalidIdTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(jwt.MapClaims)
+	}{
+		{"audience", func(c jwt.MapClaims) { c["aud"] = "someone-else" }},
+		{"issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"no expiry", func(c jwt.MapClaims) { delete(c, "exp") }},
+		{"no subject", func(c jwt.MapClaims) { delete(c, "sub") }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := oidctest.NewIssuer(t)
+			m.EditClaims(test.edit)
+			_, err := login(t, m, m.Provider(), nil, nil)
+			if !errors.Is(err, oidc.ErrIdTokenInvalid) {
+				t.Fatalf("err = %v, want ErrIdTokenInvalid", err)
+			}
+		})
+	}
+}
+
+func TestExchangeRejectsForeignSigningKey(t *testing.T) {
+	m := oidctest.NewIssuer(t)
+	_, other, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := m.Provider()
+	// The provider has fetched the keys of the issuer, the tokens are
+	// signed with another key from now on.
+	if _, err := login(t, m, p, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	m.SignWith(other)
+	_, err = login(t, m, p, nil, nil)
+	if !errors.Is(err, oidc.ErrIdTokenInvalid) {
+		t.Fatalf("err = %v, want ErrIdTokenInvalid", err)
+	}
+}
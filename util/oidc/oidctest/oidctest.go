@@ -0,0 +1,189 @@
+// Package oidctest provides an OpenID provider for tests of the login flow.
+package oidctest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"go-todo/util/oidc"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const ClientID = "go-todo"
+
+// A minimal OpenID provider: discovery, JWKS and a token endpoint that
+// checks the PKCE verifier against the challenge of the code. ID tokens are
+// for the subject "user-1" with the verified email "user@example.com" unless
+// EditClaims changes them.
+type Issuer struct {
+	server *httptest.Server
+
+	// Only the key the issuer was started with is published.
+	published ed25519.PublicKey
+
+	mu  sync.Mutex
+	key ed25519.PrivateKey
+	// code -> PKCE challenge and nonce of the login
+	challenges map[string]string
+	nonces     map[string]string
+	editClaims func(jwt.MapClaims)
+}
+
+// Starts an issuer that is closed when the test ends.
+func NewIssuer(t testing.TB) *Issuer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &Issuer{
+		published:  key.Public().(ed25519.PublicKey),
+		key:        key,
+		challenges: map[string]string{},
+		nonces:     map[string]string{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", m.jwks)
+	mux.HandleFunc("/token", m.token)
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+func (m *Issuer) URL() string {
+	return m.server.URL
+}
+
+// Settings of a client of the issuer.
+func (m *Issuer) Settings() oidc.Settings {
+	return oidc.Settings{
+		Issuer:      m.server.URL,
+		ClientID:    ClientID,
+		RedirectURL: "http://localhost/callback",
+		Scopes:      []string{"openid", "email"},
+	}
+}
+
+func (m *Issuer) Provider() *oidc.Provider {
+	return oidc.NewProvider(m.Settings(), m.server.Client())
+}
+
+// Changes the claims of the next ID tokens, e.g. to break them.
+func (m *Issuer) EditClaims(edit func(jwt.MapClaims)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.editClaims = edit
+}
+
+// Signs the next ID tokens with key, which isn't published.
+func (m *Issuer) SignWith(key ed25519.PrivateKey) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.key = key
+}
+
+// Plays the user logging in at the provider: reads the challenge and the
+// nonce from the authorization URL and returns the code to exchange.
+func (m *Issuer) Authorize(t testing.TB, authURL string) string {
+	t.Helper()
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("code_challenge_method = %q, want S256", query.Get("code_challenge_method"))
+	}
+	if query.Get("client_id") != ClientID {
+		t.Fatalf("client_id = %q, want %q", query.Get("client_id"), ClientID)
+	}
+	code, err := oidc.RandomString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.challenges[code] = query.Get("code_challenge")
+	m.nonces[code] = query.Get("nonce")
+	return code
+}
+
+func (m *Issuer) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]any{"keys": []map[string]string{{
+		"kty": "OKP",
+		"crv": "Ed25519",
+		"kid": "test",
+		"use": "sig",
+		"x":   base64.RawURLEncoding.EncodeToString(m.published),
+	}}})
+}
+
+func (m *Issuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	code := r.PostForm.Get("code")
+	m.mu.Lock()
+	challenge, ok := m.challenges[code]
+	nonce := m.nonces[code]
+	delete(m.challenges, code)
+	editClaims := m.editClaims
+	key := m.key
+	m.mu.Unlock()
+
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+	if oidc.CodeChallenge(r.PostForm.Get("code_verifier")) != challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            m.server.URL,
+		"sub":            "user-1",
+		"aud":            ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          nonce,
+		"email":          "user@example.com",
+		"email_verified": true,
+	}
+	if editClaims != nil {
+		editClaims(claims)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = "test"
+	idToken, err := token.SignedString(key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]string{"id_token": idToken, "token_type": "Bearer"})
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,88 @@
+package passwd
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	algorithmArgon2id = "argon2id"
+	argon2idPrefix    = "$argon2id$"
+	argon2SaltLength  = 16
+	argon2KeyLength   = 32
+)
+
+// Memory is in KiB.
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+var defaultArgon2Params = Argon2Params{Memory: 64 * 1024, Iterations: 3, Parallelism: 2}
+
+func isArgon2Hash(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2")
+}
+
+// Hashes are in the PHC string format also used by the reference
+// implementation, $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>.
+func hashArgon2(password string, p Argon2Params) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, argon2KeyLength)
+	return fmt.Sprintf(
+		"%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix,
+		argon2.Version,
+		p.Memory,
+		p.Iterations,
+		p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func decodeArgon2(hash string) (p Argon2Params, salt []byte, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != algorithmArgon2id {
+		return p, nil, nil, errors.New("not an argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2 version: %w", err)
+	} else if version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("unsupported argon2 version: %v", version)
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2 parameters: %w", err)
+	}
+
+	salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2 salt: %w", err)
+	}
+	key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2 key: %w", err)
+	}
+	return p, salt, key, nil
+}
+
+func compareArgon2(password string, hash string) bool {
+	p, salt, key, err := decodeArgon2(hash)
+	if err != nil || len(key) == 0 {
+		return false
+	}
+	other := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1
+}
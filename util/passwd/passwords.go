@@ -1,20 +1,190 @@
 package passwd
 
 import (
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-todo/util/config"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// How new hashes are made. Hashes made with other settings still work and are
+// reported by NeedsRehash.
+type Params struct {
+	Algorithm  string
+	Argon2     Argon2Params
+	BcryptCost int
+}
+
+var params = Params{
+	Algorithm:  algorithmArgon2id,
+	Argon2:     defaultArgon2Params,
+	BcryptCost: 14,
+}
+
+const algorithmBcrypt = "bcrypt"
+
+// Hash of a random password with the bcrypt cost hashes had before they were
+// configurable. Used by CompareDummy until Configure makes one with the
+// current settings, and ConfigureDummy one as slow as the stored hashes.
+var dummyHash = "$2a$14$3MtcPwh82B9f6tRt8oP2/.V/I25fZl5s2PIzUkHI.uJAMEasZ3cSa"
+
+// Sets the hashing parameters from the config. Should be called once at
+// startup.
+func Configure(cfg *config.Config) error {
+	p := Params{
+		Algorithm: cfg.PasswordHashAlgorithm,
+		Argon2: Argon2Params{
+			Memory:      uint32(cfg.PasswordArgon2Memory),
+			Iterations:  uint32(cfg.PasswordArgon2Iterations),
+			Parallelism: uint8(cfg.PasswordArgon2Parallelism),
+		},
+		BcryptCost: cfg.PasswordBcryptCost,
+	}
+	switch p.Algorithm {
+	case algorithmArgon2id:
+		if cfg.PasswordArgon2Memory < 8*cfg.PasswordArgon2Parallelism || cfg.PasswordArgon2Iterations < 1 ||
+			cfg.PasswordArgon2Parallelism < 1 || cfg.PasswordArgon2Parallelism > 255 {
+			return fmt.Errorf("invalid argon2 parameters: %+v", p.Argon2)
+		}
+	case algorithmBcrypt:
+		if p.BcryptCost < bcrypt.MinCost || p.BcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("invalid bcrypt cost: %v", p.BcryptCost)
+		}
+	default:
+		return fmt.Errorf("unknown password hash algorithm: %v", p.Algorithm)
+	}
+	params = p
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return fmt.Errorf("failed to generate dummy password: %w", err)
+	}
+	hash, err := Hash(string(random))
+	if err != nil {
+		return err
+	}
+	dummyHash = hash
+	return nil
+}
+
+// Makes CompareDummy as slow as the slowest of the formats of the stored
+// hashes from GetPasswordHashFormats and the current settings. Otherwise users
+// whose hash hasn't been upgraded yet, e.g. the bcrypt hashes from before
+// argon2id, would answer slower than unknown usernames. Should be called at
+// startup after Configure.
+func ConfigureDummy(formats []string) error {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return fmt.Errorf("failed to generate dummy password: %w", err)
+	}
+
+	slowest, slowestTime := dummyHash, timeCompare(dummyHash)
+	for _, format := range formats {
+		hash, err := hashLike(format, string(random))
+		if err != nil {
+			return fmt.Errorf("failed to make dummy hash for %v: %w", format, err)
+		}
+		if elapsed := timeCompare(hash); elapsed > slowestTime {
+			slowest, slowestTime = hash, elapsed
+		}
+	}
+	dummyHash = slowest
+	return nil
+}
+
+// Hashes the password with the algorithm and parameters of the format.
+func hashLike(format string, password string) (string, error) {
+	if strings.HasPrefix(format, argon2idPrefix) {
+		var version int
+		var p Argon2Params
+		_, err := fmt.Sscanf(
+			strings.TrimPrefix(format, argon2idPrefix),
+			"v=%d$m=%d,t=%d,p=%d",
+			&version, &p.Memory, &p.Iterations, &p.Parallelism,
+		)
+		if err != nil {
+			return "", fmt.Errorf("invalid argon2 parameters: %w", err)
+		}
+		return hashArgon2(password, p)
+	}
+
+	parts := strings.Split(format, "$")
+	if len(parts) < 3 {
+		return "", errors.New("unknown hash format")
+	}
+	cost, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("invalid bcrypt cost: %w", err)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func timeCompare(hash string) time.Duration {
+	start := time.Now()
+	Compare("", hash)
+	return time.Since(start)
+}
+
+// Hashes the password with the configured algorithm. The hash contains the
+// algorithm and its parameters.
 func Hash(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	if params.Algorithm == algorithmBcrypt {
+		bytes, err := bcrypt.GenerateFromPassword([]byte(password), params.BcryptCost)
+		if err != nil {
+			return "", fmt.Errorf("error in HashPassword: %w", err)
+		}
+		return string(bytes), nil
+	}
+
+	hash, err := hashArgon2(password, params.Argon2)
 	if err != nil {
 		return "", fmt.Errorf("error in HashPassword: %w", err)
 	}
-	return string(bytes), nil
+	return hash, nil
 }
 
+// Accepts hashes of every supported algorithm regardless of the current
+// settings.
 func Compare(password string, hash string) bool {
+	if isArgon2Hash(hash) {
+		return compareArgon2(password, hash)
+	}
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// Tells whether the hash was made with another algorithm or other parameters
+// than Hash would use now. The password should then be hashed again when it
+// is known, i.e. after a successful login.
+func NeedsRehash(hash string) bool {
+	if params.Algorithm == algorithmBcrypt {
+		if isArgon2Hash(hash) {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != params.BcryptCost
+	}
+
+	if !strings.HasPrefix(hash, argon2idPrefix) {
+		return true
+	}
+	hashParams, _, _, err := decodeArgon2(hash)
+	return err != nil || hashParams != params.Argon2
+}
+
+// Does the work of Compare without a user, so a login to an unknown username
+// takes as long as one with a wrong password. Always returns false.
+func CompareDummy(password string) bool {
+	Compare(password, dummyHash)
+	return false
+}
@@ -0,0 +1,92 @@
+package pat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go-todo/util/securetoken"
+)
+
+// Personal access tokens start with the prefix so they are told apart from
+// jwts and can be found by secret scanners.
+const Prefix = "gtp_"
+
+var ErrTokenInvalid = errors.New("personal access token is invalid or expired")
+
+// Resources a token can be given access to. Each has a read and a write
+// scope, e.g. lists:read and lists:write. Write does not include read.
+var Resources = []string{"lists", "todos", "time", "templates", "stats"}
+
+// The user a personal access token belongs to and what it may do.
+type Token struct {
+	ID       string
+	UserID   string
+	Username string
+	Scopes   []string
+	// Zero if the token doesn't expire.
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+type Store interface {
+	// Returns the token with the hash if it hasn't expired or been deleted,
+	// and records its use.
+	Authenticate(ctx context.Context, tokenHash string) (*Token, error)
+}
+
+var store Store
+
+// Sets the store used by Authenticate. Should be called once at startup.
+func SetStore(s Store) {
+	store = s
+}
+
+// Returns a new token and the hash to store for it.
+func Generate() (token string, hash string, err error) {
+	random, _, err := securetoken.Generate()
+	if err != nil {
+		return "", "", err
+	}
+	token = Prefix + random
+	return token, securetoken.Hash(token), nil
+}
+
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, Prefix)
+}
+
+func Authenticate(ctx context.Context, token string) (*Token, error) {
+	if store == nil {
+		return nil, errors.New("personal access token store is not set")
+	}
+	return store.Authenticate(ctx, securetoken.Hash(token))
+}
+
+// Returns the scope needed to read or write the resource.
+func Scope(resource string, write bool) string {
+	if write {
+		return resource + ":write"
+	}
+	return resource + ":read"
+}
+
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		resource, access, _ := strings.Cut(scope, ":")
+		if !slices.Contains(Resources, resource) || (access != "read" && access != "write") {
+			return fmt.Errorf("unknown scope: %v", scope)
+		}
+	}
+	return nil
+}
+
+func (t *Token) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
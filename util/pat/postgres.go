@@ -0,0 +1,55 @@
+package pat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "go-todo/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Last use is recorded at most this often per token.
+const touchInterval = time.Minute
+
+type PostgresStore struct {
+	queries *db.Queries
+}
+
+func NewPostgresStore(queries *db.Queries) *PostgresStore {
+	return &PostgresStore{queries}
+}
+
+func (s *PostgresStore) Authenticate(ctx context.Context, tokenHash string) (*Token, error) {
+	now := time.Now().UTC()
+	row, err := s.queries.GetValidPersonalAccessToken(ctx, db.GetValidPersonalAccessTokenParams{
+		TokenHash: tokenHash,
+		ExpiresAt: pgtype.Timestamp{Time: now, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTokenInvalid
+		}
+		return nil, fmt.Errorf("failed to get personal access token: %w", err)
+	}
+
+	if err := s.queries.TouchPersonalAccessToken(ctx, db.TouchPersonalAccessTokenParams{
+		UsedAt:     pgtype.Timestamp{Time: now, Valid: true},
+		ID:         row.ID,
+		UsedBefore: pgtype.Timestamp{Time: now.Add(-touchInterval), Valid: true},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to record personal access token use: %w", err)
+	}
+
+	return &Token{
+		ID:        row.ID,
+		UserID:    row.UserID,
+		Username:  row.Username,
+		Scopes:    row.Scopes,
+		ExpiresAt: row.ExpiresAt.Time,
+		CreatedAt: row.CreatedAt.Time,
+	}, nil
+}
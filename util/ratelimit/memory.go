@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const memorySweepInterval = time.Minute
+
+type memoryCounter struct {
+	hits    int
+	resetAt time.Time
+}
+
+// Store for a single instance. Every instance counts its own requests.
+type MemoryStore struct {
+	mu        sync.Mutex
+	counters  map[string]*memoryCounter
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		counters:  map[string]*memoryCounter{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryStore) Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep()
+
+	resetAt := windowEnd(time.Now(), window)
+	counter, ok := s.counters[key]
+	if !ok || !counter.resetAt.Equal(resetAt) {
+		counter = &memoryCounter{resetAt: resetAt}
+		s.counters[key] = counter
+	}
+	counter.hits++
+	return counter.hits, counter.resetAt, nil
+}
+
+// Removes counters of windows that have ended. Runs at most once per
+// memorySweepInterval. Must be called with the lock held.
+func (s *MemoryStore) sweep() {
+	now := time.Now()
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, counter := range s.counters {
+		if !counter.resetAt.After(now) {
+			delete(s.counters, key)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	db "go-todo/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const postgresCleanupInterval = time.Minute
+
+// Store shared by all instances using the same database.
+type PostgresStore struct {
+	db          *db.Queries
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+func NewPostgresStore(queries *db.Queries) *PostgresStore {
+	return &PostgresStore{db: queries, lastCleanup: time.Now()}
+}
+
+func (s *PostgresStore) Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	if err := s.cleanup(ctx); err != nil {
+		return 0, time.Time{}, err
+	}
+	args := &db.HitRateLimitParams{
+		Key:     key,
+		ResetAt: pgtype.Timestamp{Time: windowEnd(time.Now(), window).UTC(), Valid: true},
+	}
+	row, err := s.db.HitRateLimit(ctx, *args)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return int(row.Hits), row.ResetAt.Time, nil
+}
+
+// Deletes counters of windows that have ended. Runs at most once per
+// postgresCleanupInterval.
+func (s *PostgresStore) cleanup(ctx context.Context) error {
+	s.mu.Lock()
+	if time.Since(s.lastCleanup) < postgresCleanupInterval {
+		s.mu.Unlock()
+		return nil
+	}
+	s.lastCleanup = time.Now()
+	s.mu.Unlock()
+
+	_, err := s.db.DeleteExpiredRateLimits(ctx)
+	return err
+}
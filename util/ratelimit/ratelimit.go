@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"go-todo/util/config"
+)
+
+// Counts requests per key in fixed windows.
+type Store interface {
+	// Counts a request for the key in the current window of the given length.
+	// Returns the number of requests in the window, including this one, and
+	// when the window ends.
+	Hit(ctx context.Context, key string, window time.Duration) (hits int, resetAt time.Time, err error)
+}
+
+// Allows Requests requests per Window for each client. The name separates the
+// counters of different policies.
+type Policy struct {
+	Name     string
+	Requests int
+	Window   time.Duration
+}
+
+// A policy that allows nothing is treated as disabled.
+func (p Policy) Enabled() bool {
+	return p.Requests > 0 && p.Window > 0
+}
+
+func AuthPolicy(cfg *config.Config) Policy {
+	return Policy{
+		Name:     "auth",
+		Requests: cfg.RateLimitAuthRequests,
+		Window:   time.Duration(cfg.RateLimitAuthWindow) * time.Second,
+	}
+}
+
+func ListPolicy(cfg *config.Config) Policy {
+	return Policy{
+		Name:     "list",
+		Requests: cfg.RateLimitListRequests,
+		Window:   time.Duration(cfg.RateLimitListWindow) * time.Second,
+	}
+}
+
+func DefaultPolicy(cfg *config.Config) Policy {
+	return Policy{
+		Name:     "default",
+		Requests: cfg.RateLimitDefaultRequests,
+		Window:   time.Duration(cfg.RateLimitDefaultWindow) * time.Second,
+	}
+}
+
+// Returns the end of the window the time falls in. Windows of the same length
+// line up for every key and instance.
+func windowEnd(t time.Time, window time.Duration) time.Time {
+	return t.Truncate(window).Add(window)
+}
+
+var store Store = NewMemoryStore()
+
+// Replaces the store used by the package level functions. Should be called
+// once at startup.
+func SetStore(s Store) {
+	store = s
+}
+
+func Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	return store.Hit(ctx, key, window)
+}
@@ -0,0 +1,121 @@
+package registration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	db "go-todo/db/sqlc"
+	"go-todo/gterrors"
+	"go-todo/util/config"
+	"go-todo/util/passwd"
+	"go-todo/util/validate"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Mode string
+
+const (
+	// Anyone can create an account.
+	ModeOpen Mode = "open"
+	// Only admins can create accounts.
+	ModeClosed Mode = "closed"
+	// Accounts need an invite code from an admin.
+	ModeInvite Mode = "invite"
+)
+
+var mode = ModeOpen
+
+// Reads the registration mode from the config. Should be called once at
+// startup.
+func Configure(cfg *config.Config) error {
+	switch m := Mode(cfg.RegistrationMode); m {
+	case ModeOpen, ModeClosed, ModeInvite:
+		mode = m
+	default:
+		return fmt.Errorf("unknown registration mode: %v", cfg.RegistrationMode)
+	}
+	return nil
+}
+
+func GetMode() Mode {
+	return mode
+}
+
+// Creates an admin after checking the username and password like for any
+// other user.
+func CreateAdmin(ctx context.Context, queries *db.Queries, username, password string) (*db.CreateUserRow, error) {
+	if err := validate.Password(password, username); err != nil {
+		return nil, err
+	}
+	isUsernameValid, err := validate.Username(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate username: %w", err)
+	} else if !isUsernameValid {
+		return nil, gterrors.ErrUsernameUnsatisfied
+	}
+
+	passwdHash, err := passwd.Hash(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	user, err := queries.CreateUser(ctx, db.CreateUserParams{
+		ID:           uuid.New().String(),
+		Username:     username,
+		PasswordHash: passwdHash,
+		IsAdmin:      true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create admin: %w", err)
+	}
+	return &user, nil
+}
+
+// Creates the admin in ADMIN_USERNAME and ADMIN_PASSWORD if the setting is
+// used and there is no admin yet. Returns nil if no admin was created.
+func BootstrapAdmin(ctx context.Context, cfg *config.Config, queries *db.Queries) (*db.CreateUserRow, error) {
+	if cfg.AdminUsername == "" {
+		return nil, nil
+	}
+	if cfg.AdminPassword == "" {
+		return nil, errors.New("ADMIN_USERNAME is set without ADMIN_PASSWORD")
+	}
+
+	if hasAdmin, err := hasAdmin(ctx, queries); err != nil || hasAdmin {
+		return nil, err
+	}
+	errUsernameTaken := fmt.Errorf(
+		"ADMIN_USERNAME %v belongs to a user who isn't an admin, make them one or pick another username",
+		cfg.AdminUsername,
+	)
+	if _, err := queries.GetUserByUsername(ctx, cfg.AdminUsername); err == nil {
+		return nil, errUsernameTaken
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	user, err := CreateAdmin(ctx, queries, cfg.AdminUsername, cfg.AdminPassword)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		// Another instance starting at the same time created the admin.
+		if hasAdmin, err := hasAdmin(ctx, queries); err != nil || hasAdmin {
+			return nil, err
+		}
+		return nil, errUsernameTaken
+	}
+	return user, err
+}
+
+func hasAdmin(ctx context.Context, queries *db.Queries) (bool, error) {
+	admins, err := queries.CountUsers(ctx, db.CountUsersParams{
+		IsAdmin: pgtype.Bool{Bool: true, Valid: true},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to count admins: %w", err)
+	}
+	return admins > 0, nil
+}
@@ -0,0 +1,116 @@
+package revocation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-todo/util/jwt"
+)
+
+const memorySweepInterval = time.Minute
+
+type userRevocation struct {
+	revokedBefore time.Time
+	expiresAt     time.Time
+}
+
+// Store for a single instance. Revocations are lost on restart.
+type MemoryStore struct {
+	mu        sync.Mutex
+	tokens    map[string]time.Time
+	families  map[string]time.Time
+	users     map[string]userRevocation
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		tokens:    map[string]time.Time{},
+		families:  map[string]time.Time{},
+		users:     map[string]userRevocation{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep()
+	s.tokens[jti] = expiresAt
+	return nil
+}
+
+func (s *MemoryStore) RevokeFamily(ctx context.Context, family string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep()
+	if old, ok := s.families[family]; !ok || expiresAt.After(old) {
+		s.families[family] = expiresAt
+	}
+	return nil
+}
+
+func (s *MemoryStore) RevokeUser(
+	ctx context.Context,
+	userID string,
+	revokedBefore time.Time,
+	expiresAt time.Time,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep()
+	if old, ok := s.users[userID]; ok {
+		if old.revokedBefore.After(revokedBefore) {
+			revokedBefore = old.revokedBefore
+		}
+		if old.expiresAt.After(expiresAt) {
+			expiresAt = old.expiresAt
+		}
+	}
+	s.users[userID] = userRevocation{revokedBefore: revokedBefore, expiresAt: expiresAt}
+	return nil
+}
+
+func (s *MemoryStore) IsRevoked(ctx context.Context, claims *jwt.GtClaims) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if expiresAt, ok := s.tokens[claims.ID]; ok && expiresAt.After(now) {
+		return true, nil
+	}
+	if expiresAt, ok := s.families[claims.Family]; ok && expiresAt.After(now) {
+		return true, nil
+	}
+	if user, ok := s.users[claims.Subject]; ok && user.expiresAt.After(now) {
+		if claims.IssuedAt == nil || claims.IssuedAt.Time.Before(user.revokedBefore) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Removes expired entries. Runs at most once per memorySweepInterval. Must be
+// called with the lock held.
+func (s *MemoryStore) sweep() {
+	now := time.Now()
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for jti, expiresAt := range s.tokens {
+		if !expiresAt.After(now) {
+			delete(s.tokens, jti)
+		}
+	}
+	for family, expiresAt := range s.families {
+		if !expiresAt.After(now) {
+			delete(s.families, family)
+		}
+	}
+	for userID, user := range s.users {
+		if !user.expiresAt.After(now) {
+			delete(s.users, userID)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package revocation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	db "go-todo/db/sqlc"
+	"go-todo/util/jwt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const postgresCleanupInterval = time.Minute
+
+// Store shared by all instances using the same database.
+type PostgresStore struct {
+	db          *db.Queries
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+func NewPostgresStore(queries *db.Queries) *PostgresStore {
+	return &PostgresStore{db: queries, lastCleanup: time.Now()}
+}
+
+func (s *PostgresStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	if err := s.cleanup(ctx); err != nil {
+		return err
+	}
+	args := &db.RevokeAccessTokenParams{
+		Jti:       jti,
+		ExpiresAt: pgtype.Timestamp{Time: expiresAt.UTC(), Valid: true},
+	}
+	return s.db.RevokeAccessToken(ctx, *args)
+}
+
+func (s *PostgresStore) RevokeFamily(ctx context.Context, family string, expiresAt time.Time) error {
+	if err := s.cleanup(ctx); err != nil {
+		return err
+	}
+	args := &db.RevokeTokenFamilyParams{
+		Family:    family,
+		ExpiresAt: pgtype.Timestamp{Time: expiresAt.UTC(), Valid: true},
+	}
+	return s.db.RevokeTokenFamily(ctx, *args)
+}
+
+func (s *PostgresStore) RevokeUser(
+	ctx context.Context,
+	userID string,
+	revokedBefore time.Time,
+	expiresAt time.Time,
+) error {
+	if err := s.cleanup(ctx); err != nil {
+		return err
+	}
+	args := &db.RevokeUserTokensParams{
+		UserID:        userID,
+		RevokedBefore: pgtype.Timestamp{Time: revokedBefore.UTC(), Valid: true},
+		ExpiresAt:     pgtype.Timestamp{Time: expiresAt.UTC(), Valid: true},
+	}
+	return s.db.RevokeUserTokens(ctx, *args)
+}
+
+func (s *PostgresStore) IsRevoked(ctx context.Context, claims *jwt.GtClaims) (bool, error) {
+	issuedAt := time.Time{}
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time.UTC()
+	}
+	args := &db.IsAccessTokenRevokedParams{
+		Jti:      claims.ID,
+		Family:   claims.Family,
+		UserID:   claims.Subject,
+		IssuedAt: pgtype.Timestamp{Time: issuedAt, Valid: true},
+	}
+	return s.db.IsAccessTokenRevoked(ctx, *args)
+}
+
+// Deletes expired revocations. Runs at most once per postgresCleanupInterval.
+func (s *PostgresStore) cleanup(ctx context.Context) error {
+	s.mu.Lock()
+	if time.Since(s.lastCleanup) < postgresCleanupInterval {
+		s.mu.Unlock()
+		return nil
+	}
+	s.lastCleanup = time.Now()
+	s.mu.Unlock()
+
+	if _, err := s.db.DeleteExpiredRevokedAccessTokens(ctx); err != nil {
+		return err
+	}
+	if _, err := s.db.DeleteExpiredRevokedTokenFamilies(ctx); err != nil {
+		return err
+	}
+	_, err := s.db.DeleteExpiredRevokedUserTokens(ctx)
+	return err
+}
@@ -0,0 +1,63 @@
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-todo/util/config"
+	"go-todo/util/jwt"
+)
+
+// Keeps track of access tokens that must not be accepted anymore even though
+// they haven't expired. Entries only need to live as long as the tokens they
+// revoke.
+type Store interface {
+	// Revokes a single access token.
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	// Revokes all access tokens of a session.
+	RevokeFamily(ctx context.Context, family string, expiresAt time.Time) error
+	// Revokes all access tokens of the user issued before revokedBefore.
+	RevokeUser(ctx context.Context, userID string, revokedBefore time.Time, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, claims *jwt.GtClaims) (bool, error)
+}
+
+var store Store = NewMemoryStore()
+
+// Replaces the store used by the package level functions. Should be called
+// once at startup.
+func SetStore(s Store) {
+	store = s
+}
+
+func IsRevoked(ctx context.Context, claims *jwt.GtClaims) (bool, error) {
+	return store.IsRevoked(ctx, claims)
+}
+
+func RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	return store.RevokeToken(ctx, jti, expiresAt)
+}
+
+// Revokes the access tokens issued for the session with the given refresh
+// token family.
+func RevokeFamily(ctx context.Context, family string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to revoke token family: %w", err)
+	}
+	expiresAt := time.Now().UTC().Add(time.Minute * time.Duration(cfg.AccessTokenLifeSpan))
+	return store.RevokeFamily(ctx, family, expiresAt)
+}
+
+// Revokes every access token issued to the user so far. Tokens issued during
+// the current second stay valid, because issued at times only have a second
+// precision and the caller might issue a new token right after.
+func RevokeUser(ctx context.Context, userID string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	expiresAt := now.Add(time.Minute * time.Duration(cfg.AccessTokenLifeSpan))
+	return store.RevokeUser(ctx, userID, now, expiresAt)
+}
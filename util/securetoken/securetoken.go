@@ -0,0 +1,29 @@
+package securetoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+const tokenBytes = 32
+
+// Returns a random URL safe token and its hash. Only the hash should be
+// stored.
+func Generate() (token string, hash string, err error) {
+	raw := make([]byte, tokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(raw)
+	return token, Hash(token), nil
+}
+
+// Hashes a token for storage and lookup. The tokens are random, so a plain
+// SHA-256 is enough.
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,120 @@
+package tokengc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	db "go-todo/db/sqlc"
+	"go-todo/logging"
+	"go-todo/util/config"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Removes expired refresh tokens and used refresh tokens older than a grace
+// period from jwt_tokens. Rows are deleted in small batches so hot rows are
+// never locked for long. Sessions are only removed once their family has no
+// tokens left, so Refresh can still tell a collected token of a live session
+// was reused.
+type Collector struct {
+	db        *db.Queries
+	batchSize int32
+	usedGrace time.Duration
+	interval  time.Duration
+}
+
+type Result struct {
+	Expired int64
+	Used    int64
+	// Sessions whose refresh tokens were all deleted
+	Sessions int64
+}
+
+func New(queries *db.Queries, cfg *config.Config) *Collector {
+	batchSize := cfg.TokenGcBatchSize
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	return &Collector{
+		db:        queries,
+		batchSize: int32(batchSize),
+		usedGrace: time.Minute * time.Duration(cfg.TokenGcUsedGrace),
+		interval:  time.Minute * time.Duration(cfg.TokenGcInterval),
+	}
+}
+
+// Runs one collection until there is nothing left to delete and logs the
+// counts.
+func (c *Collector) Run(ctx context.Context) (Result, error) {
+	start := time.Now()
+	var result Result
+
+	for {
+		rows, err := c.db.DeleteExpiredJwtTokens(ctx, c.batchSize)
+		if err != nil {
+			return result, fmt.Errorf("failed to delete expired jwts: %w", err)
+		}
+		result.Expired += rows
+		if rows < int64(c.batchSize) {
+			break
+		}
+	}
+
+	usedBefore := pgtype.Timestamp{Time: time.Now().UTC().Add(-c.usedGrace), Valid: true}
+	for {
+		args := &db.DeleteUsedJwtTokensParams{
+			UsedBefore: usedBefore,
+			BatchSize:  c.batchSize,
+		}
+		rows, err := c.db.DeleteUsedJwtTokens(ctx, *args)
+		if err != nil {
+			return result, fmt.Errorf("failed to delete used jwts: %w", err)
+		}
+		result.Used += rows
+		if rows < int64(c.batchSize) {
+			break
+		}
+	}
+
+	// A session is saved right after its first token. The minute keeps the
+	// collector from deleting a session between the two.
+	createdBefore := pgtype.Timestamp{Time: time.Now().UTC().Add(-time.Minute), Valid: true}
+	rows, err := c.db.DeleteOrphanedSessions(ctx, createdBefore)
+	if err != nil {
+		return result, fmt.Errorf("failed to delete orphaned sessions: %w", err)
+	}
+	result.Sessions = rows
+
+	logging.LogMaintenanceEvent(
+		"jwt-gc",
+		time.Since(start),
+		slog.Int64("expired", result.Expired),
+		slog.Int64("used", result.Used),
+		slog.Int64("sessions", result.Sessions),
+	)
+	return result, nil
+}
+
+// Runs the collector every interval until ctx is done. Does nothing if the
+// interval is zero.
+func (c *Collector) Start(ctx context.Context) {
+	if c.interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := c.Run(ctx); err != nil {
+					logging.LogError(err, "tokengc", "Failed to collect jwt garbage.")
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,100 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RFC 6238 defaults that authenticator apps expect.
+const (
+	period = 30
+	digits = 6
+	// Number of time steps accepted before and after the current one to
+	// allow for clock drift.
+	skew = 1
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Returns a new random base32 encoded secret.
+func GenerateSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate totp secret: %w", err)
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+// Returns the otpauth URI used to add the secret to an authenticator app.
+func URI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(digits))
+	params.Set("period", fmt.Sprint(period))
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+func code(key []byte, step int64) string {
+	mac := hmac.New(sha1.New, key)
+	binary.Write(mac, binary.BigEndian, step)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", digits, value%1_000_000)
+}
+
+// Checks the code against the secret at time t. Returns the time step the code
+// matched so callers can refuse a step that has already been used.
+func Validate(secret, input string, t time.Time) (step int64, ok bool) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+	input = strings.ReplaceAll(input, " ", "")
+	if len(input) != digits {
+		return 0, false
+	}
+
+	current := t.Unix() / period
+	for s := current - skew; s <= current+skew; s++ {
+		if subtle.ConstantTimeCompare([]byte(code(key, s)), []byte(input)) == 1 {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// Returns n random recovery codes like "abcde-fghij".
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for range n {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		encoded := strings.ToLower(encoding.EncodeToString(raw))[:10]
+		codes = append(codes, encoded[:5]+"-"+encoded[5:])
+	}
+	return codes, nil
+}
+
+// Hashes a recovery code for storage. The codes are random, so a plain
+// SHA-256 is enough and lets them be looked up directly.
+func HashRecoveryCode(recoveryCode string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(recoveryCode))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
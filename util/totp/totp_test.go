@@ -0,0 +1,134 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// The SHA1 secret of RFC 6238, "12345678901234567890", in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateRfc6238Vectors(t *testing.T) {
+	// The RFC lists 8 digit codes, apps use the last 6.
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, test.code, time.Unix(test.unix, 0))
+			if !ok {
+				t.Fatalf("code %v rejected at %v", test.code, test.unix)
+			}
+			if want := test.unix / period; step != want {
+				t.Errorf("got step %v, want %v", step, want)
+			}
+		})
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	key, err := encoding.DecodeString(rfcSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const step = 50_000_000
+	valid := code(key, step)
+	tests := []struct {
+		name   string
+		offset int64
+		ok     bool
+	}{
+		{"two steps early", -2 * period, false},
+		{"one step early", -period, true},
+		{"current step", 0, true},
+		{"end of the step", period - 1, true},
+		{"one step late", period, true},
+		{"two steps late", 2 * period, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := Validate(rfcSecret, valid, time.Unix(step*period+test.offset, 0))
+			if ok != test.ok {
+				t.Fatalf("got %v, want %v", ok, test.ok)
+			}
+			if ok && got != step {
+				t.Errorf("got step %v, want %v", got, step)
+			}
+		})
+	}
+}
+
+func TestValidateInput(t *testing.T) {
+	at := time.Unix(59, 0)
+	tests := []struct {
+		name   string
+		secret string
+		input  string
+		ok     bool
+	}{
+		{"code", rfcSecret, "287082", true},
+		{"spaces", rfcSecret, "287 082", true},
+		{"lowercase secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "287082", true},
+		{"wrong code", rfcSecret, "287083", false},
+		{"too short", rfcSecret, "28708", false},
+		{"8 digits", rfcSecret, "94287082", false},
+		{"empty", rfcSecret, "", false},
+		{"invalid secret", "not base32!", "287082", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, ok := Validate(test.secret, test.input, at); ok != test.ok {
+				t.Errorf("got %v, want %v", ok, test.ok)
+			}
+		})
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	want := HashRecoveryCode("abcde-fghij")
+	tests := []struct {
+		input string
+		same  bool
+	}{
+		{"abcde-fghij", true},
+		{"ABCDE-FGHIJ", true},
+		{"abcdefghij", true},
+		{"abcde fghij", true},
+		{" Abcde - Fghij ", true},
+		{"abcde-fghik", false},
+		{"abcde_fghij", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			if got := HashRecoveryCode(test.input); (got == want) != test.same {
+				t.Errorf("same hash as abcde-fghij is %v, want %v", !test.same, test.same)
+			}
+		})
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for _, recoveryCode := range codes {
+		if len(recoveryCode) != 11 || recoveryCode[5] != '-' {
+			t.Errorf("unexpected format: %q", recoveryCode)
+		}
+		if seen[HashRecoveryCode(recoveryCode)] {
+			t.Errorf("duplicate code: %q", recoveryCode)
+		}
+		seen[HashRecoveryCode(recoveryCode)] = true
+	}
+}
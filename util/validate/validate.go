@@ -1,45 +1,227 @@
 package validate
 
 import (
+	"bufio"
 	"fmt"
+	"net/mail"
+	"os"
 	"regexp"
+	"strings"
+	"time"
+	// Time zones are known even without zoneinfo on the host.
+	_ "time/tzdata"
+	"unicode"
+
+	"go-todo/gterrors"
+	"go-todo/util/config"
+
+	"golang.org/x/text/language"
 )
 
+type limits struct {
+	titleMax       int
+	descriptionMax int
+	usernameMin    int
+	usernameMax    int
+	displayNameMax int
+}
+
+type passwordPolicy struct {
+	minLength      int
+	maxLength      int
+	requireLetter  bool
+	requireUpper   bool
+	requireNumber  bool
+	requireSpecial bool
+	denyUsername   bool
+	denyList       map[string]struct{}
+}
+
+// Used until Configure is called.
+var currentLimits = limits{
+	titleMax:       40,
+	descriptionMax: 150,
+	usernameMin:    3,
+	usernameMax:    20,
+	displayNameMax: 50,
+}
+
+var currentPolicy = passwordPolicy{
+	minLength:      8,
+	maxLength:      32,
+	requireLetter:  true,
+	requireNumber:  true,
+	requireSpecial: true,
+	denyUsername:   true,
+}
+
+// Sets the limits and the password policy from the config and loads the
+// password deny-list. Should be called once at startup.
+func Configure(cfg *config.Config) error {
+	denyList := map[string]struct{}{}
+	if cfg.PasswordDenyListFile != "" {
+		var err error
+		denyList, err = loadDenyList(cfg.PasswordDenyListFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	currentLimits = limits{
+		titleMax:       cfg.TitleMaxLength,
+		descriptionMax: cfg.DescriptionMaxLength,
+		usernameMin:    cfg.UsernameMinLength,
+		usernameMax:    cfg.UsernameMaxLength,
+		displayNameMax: cfg.DisplayNameMaxLength,
+	}
+	currentPolicy = passwordPolicy{
+		minLength:      cfg.PasswordMinLength,
+		maxLength:      cfg.PasswordMaxLength,
+		requireLetter:  cfg.PasswordRequireLetter,
+		requireUpper:   cfg.PasswordRequireUpper,
+		requireNumber:  cfg.PasswordRequireNumber,
+		requireSpecial: cfg.PasswordRequireSpecial,
+		denyUsername:   cfg.PasswordDenyUsername,
+		denyList:       denyList,
+	}
+	return nil
+}
+
+// Reads a file with one password per line. Empty lines and lines starting
+// with # are skipped. Passwords are matched case-insensitively.
+func loadDenyList(path string) (map[string]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open password deny-list: %w", err)
+	}
+	defer file.Close()
+
+	denyList := map[string]struct{}{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		denyList[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read password deny-list: %w", err)
+	}
+	return denyList, nil
+}
+
 // Returns true if the str has lower or equal number of chars than length.
 func stringLength(str string, length int) bool {
 	return len([]rune(str)) <= length
 }
 
 func LengthDescription(txt string) bool {
-	return stringLength(txt, 150)
+	return stringLength(txt, currentLimits.descriptionMax)
 }
 
 func LengthTitle(txt string) bool {
-	return stringLength(txt, 40)
+	return stringLength(txt, currentLimits.titleMax)
 }
 
-func Password(password string) (bool, error) {
-	if length := len(password); length < 8 || length > 32 {
-		return false, nil
+// Checks the password against the password policy. Returns an error wrapping
+// gterrors.ErrPasswordUnsatisfied that tells which requirement failed.
+func Password(password, username string) error {
+	policy := currentPolicy
+	unsatisfied := func(reason string) error {
+		return fmt.Errorf("%w: %v", gterrors.ErrPasswordUnsatisfied, reason)
 	}
-	hasLetter, err := regexp.MatchString(`\p{L}`, password)
-	if err != nil {
-		return false, fmt.Errorf("failed to match letter regex: %w", err)
+
+	length := len([]rune(password))
+	if length < policy.minLength || length > policy.maxLength {
+		return unsatisfied(fmt.Sprintf(
+			"length must be between %d and %d characters",
+			policy.minLength,
+			policy.maxLength,
+		))
 	}
-	hasNumber, err := regexp.MatchString(`\d`, password)
-	if err != nil {
-		return false, fmt.Errorf("failed to match number regex: %w", err)
+
+	var hasLetter, hasUpper, hasNumber, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+			hasUpper = hasUpper || unicode.IsUpper(r)
+		case unicode.IsDigit(r):
+			hasNumber = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSpecial = true
+		}
 	}
-	hasSpecialChar, err := regexp.MatchString(`\p{P}|\p{S}`, password)
-	if err != nil {
-		return false, fmt.Errorf("failed to match punctuation regex: %w", err)
+	if policy.requireLetter && !hasLetter {
+		return unsatisfied("must contain a letter")
+	}
+	if policy.requireUpper && !hasUpper {
+		return unsatisfied("must contain an uppercase letter")
+	}
+	if policy.requireNumber && !hasNumber {
+		return unsatisfied("must contain a number")
+	}
+	if policy.requireSpecial && !hasSpecial {
+		return unsatisfied("must contain a special character")
+	}
+
+	lowerPassword := strings.ToLower(password)
+	if _, ok := policy.denyList[lowerPassword]; ok {
+		return unsatisfied("password is too common")
+	}
+	if policy.denyUsername && similarToUsername(lowerPassword, strings.ToLower(username)) {
+		return unsatisfied("password is too similar to the username")
 	}
+	return nil
+}
 
-	return hasLetter && hasNumber && hasSpecialChar, nil
+// Password and username are expected in lower case.
+func similarToUsername(password, username string) bool {
+	if username == "" {
+		return false
+	}
+	reversed := []rune(password)
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+	return strings.Contains(password, username) ||
+		strings.Contains(string(reversed), username) ||
+		levenshtein(password, username) <= 2
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// Accepts a bare address like "user@example.com", without a display name.
+func Email(email string) bool {
+	if len(email) > 254 {
+		return false
+	}
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email && address.Name == ""
 }
 
 func Username(username string) (bool, error) {
-	if length := len(username); length < 3 || length > 20 {
+	if length := len([]rune(username)); length < currentLimits.usernameMin || length > currentLimits.usernameMax {
 		return false, nil
 	}
 	hasDisallowedChars, err := regexp.MatchString(`[^\p{L}\p{N}\s_-]`, username)
@@ -49,3 +231,36 @@ func Username(username string) (bool, error) {
 
 	return !hasDisallowedChars, nil
 }
+
+// Accepts any printable name up to the configured length, including an empty
+// one.
+func DisplayName(name string) bool {
+	if !stringLength(name, currentLimits.displayNameMax) {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Accepts IANA time zone names like "Europe/Helsinki" and "UTC".
+func Timezone(name string) bool {
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// Returns the canonical form of a BCP 47 language tag like "en-US", or false
+// if it isn't one.
+func Locale(locale string) (string, bool) {
+	tag, err := language.Parse(locale)
+	if err != nil || tag == language.Und {
+		return "", false
+	}
+	return tag.String(), true
+}